@@ -3,6 +3,7 @@ package iter
 import (
 	"fmt"
 	"reflect"
+	"runtime"
 	"testing"
 )
 
@@ -54,6 +55,8 @@ func BenchmarkIteration(b *testing.B) {
 				}
 			})
 		})
+	}
+	for _, benchCount := range []int{1 << 3, 1 << 7, 1 << 11, 1 << 15, 1 << 20} {
 		b.Run(fmt.Sprintf("Maps/%d", benchCount), func(b *testing.B) {
 			benchMaps := make(map[int]string, benchCount)
 			for y := 0; y < benchCount; y++ {
@@ -70,6 +73,7 @@ func BenchmarkIteration(b *testing.B) {
 				f := func(k, v reflect.Value) error {
 					return nil
 				}
+				b.ReportAllocs()
 				b.ResetTimer()
 				for i := 0; i < b.N; i++ {
 					for z, v := range benchVals {
@@ -77,11 +81,35 @@ func BenchmarkIteration(b *testing.B) {
 					}
 				}
 			})
+			b.Run("MapKeys", func(b *testing.B) {
+				f := func(k, v reflect.Value) error {
+					return nil
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					for _, key := range benchVal.MapKeys() {
+						f(key, benchVal.MapIndex(key))
+					}
+				}
+			})
 			b.Run("Iter", func(b *testing.B) {
 				it := &Iter{}
 				f := func(k, v reflect.Value) error {
 					return nil
 				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					it.IterMap(benchVal, f)
+				}
+			})
+			b.Run("IterReuseMapValues", func(b *testing.B) {
+				it := &Iter{ReuseMapValues: true}
+				f := func(k, v reflect.Value) error {
+					return nil
+				}
+				b.ReportAllocs()
 				b.ResetTimer()
 				for i := 0; i < b.N; i++ {
 					it.IterMap(benchVal, f)
@@ -89,14 +117,121 @@ func BenchmarkIteration(b *testing.B) {
 			})
 			b.Run("RecoverIter", func(b *testing.B) {
 				it := recoverIter{&Iter{}}
-				f := func(idx int, v reflect.Value) error {
+				f := func(k, v reflect.Value) error {
 					return nil
 				}
+				b.ReportAllocs()
 				b.ResetTimer()
 				for i := 0; i < b.N; i++ {
-					it.IterSlice(benchVal, f)
+					it.IterMap(benchVal, f)
+				}
+			})
+		})
+	}
+}
+
+type benchStruct struct {
+	Name    string
+	ID      int
+	Enabled bool
+	Tags    []string
+	Meta    map[string]string
+	Next    *benchStruct
+	private int
+}
+
+func BenchmarkWalk(b *testing.B) {
+	for _, benchCount := range []int{1 << 7, 100000} {
+		b.Run(fmt.Sprintf("Structs/%d", benchCount), func(b *testing.B) {
+			benchStructs := make([]benchStruct, benchCount)
+			for y := range benchStructs {
+				benchStructs[y] = benchStruct{
+					Name: fmt.Sprintf("bench struct %v", y), ID: y, Enabled: y%2 == 0}
+			}
+			benchVal := reflect.ValueOf(benchStructs)
+			f := func(el Pair) error {
+				return nil
+			}
+			b.ResetTimer()
+
+			b.Run("IterStruct", func(b *testing.B) {
+				it := &Iter{ExcludeUnexported: true}
+				f := func(field reflect.StructField, v reflect.Value) error {
+					return nil
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					for y := 0; y < benchCount; y++ {
+						it.IterStruct(benchVal.Index(y), f)
+					}
+				}
+			})
+			b.Run("Walk", func(b *testing.B) {
+				w := NewWalker(&Iter{})
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					w.Walk(benchStructs, f)
+				}
+			})
+			b.Run("WalkPoolPairs", func(b *testing.B) {
+				w := NewWalker(&Iter{}, PoolPairs())
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					w.Walk(benchStructs, f)
+				}
+			})
+			b.Run("WalkPointer", func(b *testing.B) {
+				w := NewWalker(&Iter{})
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					w.Walk(&benchStructs, f)
 				}
 			})
 		})
 	}
 }
+
+func BenchmarkWalkPairs(b *testing.B) {
+	for _, benchCount := range []int{1 << 7, 1 << 11, 1 << 15} {
+		b.Run(fmt.Sprintf("Slices/%d", benchCount), func(b *testing.B) {
+			benchPtrs := make([]*int, benchCount)
+			for y := range benchPtrs {
+				benchPtrs[y] = new(int)
+			}
+			f := func(el Pair) error {
+				return nil
+			}
+			b.ResetTimer()
+
+			b.Run("Walk", func(b *testing.B) {
+				benchAllocsPerElement(b, benchCount, func() {
+					NewWalker(&Iter{}).Walk(benchPtrs, f)
+				})
+			})
+			b.Run("WalkPoolPairs", func(b *testing.B) {
+				benchAllocsPerElement(b, benchCount, func() {
+					NewWalker(&Iter{}, PoolPairs()).Walk(benchPtrs, f)
+				})
+			})
+		})
+	}
+}
+
+// benchAllocsPerElement runs fn b.N times, reporting the allocations made for
+// each of the count elements that it visits.
+func benchAllocsPerElement(b *testing.B, count int, fn func()) {
+	var before, after runtime.MemStats
+	b.ReportAllocs()
+	b.ResetTimer()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < b.N; i++ {
+		fn()
+	}
+	runtime.ReadMemStats(&after)
+	allocs := float64(after.Mallocs - before.Mallocs)
+	b.ReportMetric(allocs/float64(b.N*count), "allocs/elem")
+}
@@ -0,0 +1,92 @@
+package iter
+
+import (
+	"container/list"
+	"container/ring"
+	"reflect"
+	"sync"
+)
+
+var (
+	listType      = reflect.TypeOf((*list.List)(nil)).Elem()
+	ringType      = reflect.TypeOf((*ring.Ring)(nil)).Elem()
+	syncMapType   = reflect.TypeOf((*sync.Map)(nil)).Elem()
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// expandContainer is like expand for the containers of the standard library
+// whose elements are held in unexported fields, calling f with a Pair for each
+// of their logical elements. Lists and rings are keyed by their position like
+// slices, their elements may be replaced with Set. A sync.Map is keyed like a
+// map, but its values can not be Set. It reports false if in is not one of
+// these containers.
+func (w *walker) expandContainer(el Pair, in reflect.Value,
+	f func(Pair) error) (bool, error) {
+	switch in.Type() {
+	case listType, ringType, syncMapType:
+	default:
+		return false, nil
+	}
+	if !in.CanInterface() {
+		return false, nil
+	}
+
+	// Methods of each container have pointer receivers. A copy of the list or
+	// ring header still refers to the original elements.
+	ptr := reflect.New(in.Type())
+	if in.CanAddr() {
+		ptr = in.Addr()
+	} else {
+		ptr.Elem().Set(in)
+	}
+
+	switch v := ptr.Interface().(type) {
+	case *list.List:
+		i := 0
+		for e := v.Front(); e != nil; e = e.Next() {
+			err := w.yieldElement(el, i, reflect.ValueOf(&e.Value).Elem(),
+				reflect.Slice, f)
+			if err != nil {
+				return true, err
+			}
+			i++
+		}
+	case *ring.Ring:
+		// Iteration ends at the element preceding the one after v rather than
+		// at v itself, which is never reached again when it is a copy.
+		first := v.Next()
+		err := w.yieldElement(el, 0, reflect.ValueOf(&v.Value).Elem(),
+			reflect.Slice, f)
+		for i, r := 1, first; err == nil; i, r = i+1, r.Next() {
+			if r.Next() == first {
+				break
+			}
+			err = w.yieldElement(el, i, reflect.ValueOf(&r.Value).Elem(),
+				reflect.Slice, f)
+		}
+		return true, err
+	case *sync.Map:
+		var err error
+		v.Range(func(key, value interface{}) bool {
+			rv := reflect.ValueOf(value)
+			if !rv.IsValid() {
+				rv = reflect.Zero(interfaceType)
+			}
+			pr := w.newPair()
+			pr.key, pr.rv, pr.via = key, rv, reflect.Map
+			pr.readonly = "sync.Map values can not be set"
+			err = w.yield(el, pr, f)
+			return err == nil
+		})
+		return true, err
+	}
+	return true, nil
+}
+
+// yieldElement yields a Pair for an element of a container held by el.
+func (w *walker) yieldElement(el Pair, key interface{}, v reflect.Value,
+	via reflect.Kind, f func(Pair) error) error {
+	pr := w.newPair()
+	pr.key, pr.rv, pr.via = key, v, via
+	return w.yield(el, pr, f)
+}
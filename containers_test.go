@@ -0,0 +1,162 @@
+package iter
+
+import (
+	"container/list"
+	"container/ring"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestContainers(t *testing.T) {
+	newList := func(vals ...interface{}) *list.List {
+		l := list.New()
+		for _, v := range vals {
+			l.PushBack(v)
+		}
+		return l
+	}
+	newRing := func(vals ...interface{}) *ring.Ring {
+		r := ring.New(len(vals))
+		for _, v := range vals {
+			r.Value = v
+			r = r.Next()
+		}
+		return r
+	}
+	newSyncMap := func(vals map[string]interface{}) *sync.Map {
+		m := new(sync.Map)
+		for k, v := range vals {
+			m.Store(k, v)
+		}
+		return m
+	}
+	visit := func(t *testing.T, w Walker, v interface{}) map[string]interface{} {
+		res := make(map[string]interface{})
+		err := w.Walk(v, func(el Pair) error {
+			res[el.Path().String()] = el.Val()
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		return res
+	}
+
+	type testContainers struct {
+		List    *list.List
+		Ring    *ring.Ring
+		SyncMap *sync.Map
+		Values  struct {
+			List list.List
+			Ring ring.Ring
+		}
+	}
+	give := &testContainers{
+		List:    newList("a", []int{1, 2}),
+		Ring:    newRing("b", "c", nil),
+		SyncMap: newSyncMap(map[string]interface{}{"d": 3, "e": nil}),
+	}
+	give.Values.List.PushBack("f")
+	give.Values.Ring.Value = "g"
+	exp := map[string]interface{}{
+		".List[0]":        "a",
+		".List[1][0]":     1,
+		".List[1][1]":     2,
+		".Ring[0]":        "b",
+		".Ring[1]":        "c",
+		".Ring[2]":        nil,
+		`.SyncMap["d"]`:   3,
+		`.SyncMap["e"]`:   nil,
+		".Values.List[0]": "f",
+		".Values.Ring[0]": "g",
+	}
+
+	walkers := map[string]Walker{
+		"Dfs":       NewWalker(NewIter()),
+		"Iterative": NewWalker(NewIter(), Iterative()),
+		"Bfs":       NewBFSWalker(NewIter()),
+		"Parallel":  NewParallelWalker(NewIter(), 4),
+	}
+	for name, w := range walkers {
+		w := w
+		t.Run(name, func(t *testing.T) {
+			if res := visit(t, w, give); !reflect.DeepEqual(exp, res) {
+				t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+			}
+
+			// Walking a copy of the struct holds copies of the list and ring.
+			if res := visit(t, w, *give); !reflect.DeepEqual(exp, res) {
+				t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+			}
+		})
+	}
+
+	t.Run("Set", func(t *testing.T) {
+		l, r := newList(1, 2), newRing(3, 4)
+		m := newSyncMap(map[string]interface{}{"a": 5})
+		var errs []error
+		err := Walk(&[]interface{}{l, r, m}, func(el Pair) error {
+			if err := el.Set(el.Val().(int) * 10); err != nil {
+				errs = append(errs, err)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if len(errs) != 1 {
+			t.Fatalf("expected Set to fail for the sync.Map, got: %v", errs)
+		}
+		if err := tchkstr(t, errs[0], `cannot set [2]["a"]: sync.Map values can not be set`); err != nil {
+			t.Fatal(err)
+		}
+		if v, _ := m.Load("a"); v != 5 {
+			t.Fatalf("expected sync.Map to be unchanged, got: %v", v)
+		}
+		if l.Front().Value != 10 || l.Back().Value != 20 {
+			t.Fatalf("expected list to be set, got: %v %v",
+				l.Front().Value, l.Back().Value)
+		}
+		if r.Value != 30 || r.Next().Value != 40 {
+			t.Fatalf("expected ring to be set, got: %v %v", r.Value, r.Next().Value)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		expErr := errors.New("propagate error")
+		for _, v := range []interface{}{newList(1, 2), newRing(1, 2),
+			newSyncMap(map[string]interface{}{"a": 1, "b": 2})} {
+			var visits int
+			err := Walk(v, func(el Pair) error {
+				visits++
+				return expErr
+			})
+			if err != expErr {
+				t.Fatalf("expected err %v, got: %v", expErr, err)
+			}
+			if visits != 1 {
+				t.Fatalf("expected 1 visit for %T, got: %v", v, visits)
+			}
+		}
+	})
+
+	t.Run("Cycles", func(t *testing.T) {
+		r := ring.New(2)
+		r.Value = r
+		var cycles int
+		err := Walk(r, func(el Pair) error {
+			if el.Err() != nil {
+				cycles++
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if cycles != 1 {
+			t.Fatalf("expected 1 cycle, got: %v", cycles)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package iter
+
+// Cursor walks a value one Pair at a time as Next is called, visiting the same
+// Pairs in the same order as the Walker returned by NewWalker given the same
+// Iterator and options. This allows several values to be walked in lockstep
+// without a Go routine for each. Like the Iterative option, the children of
+// each structured value are collected from the Iterator as the Cursor moves to
+// the first of them.
+//
+// Cursors are not safe for use by multiple Go routines.
+type Cursor struct {
+	w     walker
+	s     *stepper
+	el    Pair
+	err   error
+	enter bool
+}
+
+// NewCursor returns a new Cursor over value backed by the given Iterator, the
+// options are interpreted as they are by NewWalker.
+func NewCursor(iterator Iterator, value interface{},
+	opts ...WalkerOption) *Cursor {
+	c := &Cursor{w: newWalker(iterator, opts)}
+	c.s = newStepper(&c.w, newRootPair(value))
+	return c
+}
+
+// Next moves the Cursor to the next Pair, returning false once there are none
+// left, an error has occurred or the Cursor has been closed.
+func (c *Cursor) Next() bool {
+	if c.s == nil {
+		return false
+	}
+	if c.enter {
+		c.enter = false
+		if err := c.s.descend(c.el, nil); err != nil {
+			return c.fail(err)
+		}
+	}
+	for {
+		ev, el, err := c.s.step()
+		switch ev {
+		case stepDone:
+			return c.fail(err)
+		case stepLeaf:
+			c.el = el
+			return true
+		case stepEnter:
+			if c.w.containers {
+				c.el, c.enter = el, true
+				return true
+			}
+			if err := c.s.descend(el, nil); err != nil {
+				return c.fail(err)
+			}
+		}
+	}
+}
+
+// Pair returns the Pair the Cursor is at, or nil if Next has not returned true.
+// When pooling Pairs it is only valid until the next call to Next or Close.
+func (c *Cursor) Pair() Pair {
+	return c.el
+}
+
+// Err returns the error that ended the walk, if any.
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// Close ends the walk, releasing the values held by the Cursor. Next will
+// return false afterwards.
+func (c *Cursor) Close() {
+	c.s, c.el, c.enter = nil, nil, false
+	c.w.refs, c.w.free = nil, nil
+}
+
+func (c *Cursor) fail(err error) bool {
+	c.Close()
+	c.err = err
+	return false
+}
@@ -0,0 +1,109 @@
+package iter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	give := map[string]interface{}{
+		"a": []int{1, 2, 3},
+		"b": map[string]string{"c": "d", "e": "f"},
+		"g": struct{ H, I []string }{[]string{"j"}, nil},
+	}
+	it := &Iter{SortMapKeys: true}
+	walk := func(opts ...WalkerOption) []string {
+		var res []string
+		NewWalker(it, opts...).Walk(give, func(el Pair) error {
+			res = append(res, fmt.Sprintf("%v=%v", el.Path(), el.Val()))
+			return nil
+		})
+		return res
+	}
+	cursor := func(c *Cursor) []string {
+		var res []string
+		for c.Next() {
+			el := c.Pair()
+			res = append(res, fmt.Sprintf("%v=%v", el.Path(), el.Val()))
+		}
+		if err := c.Err(); err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		return res
+	}
+
+	tests := map[string][]WalkerOption{
+		"Default":         nil,
+		"VisitContainers": {VisitContainers()},
+		"PoolPairs":       {PoolPairs()},
+		"MaxDepth":        {MaxDepth(1), VisitContainers()},
+	}
+	for name, opts := range tests {
+		opts := opts
+		t.Run(name, func(t *testing.T) {
+			exp, res := walk(opts...), cursor(NewCursor(it, give, opts...))
+			if !reflect.DeepEqual(exp, res) {
+				t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+			}
+		})
+	}
+
+	t.Run("Lockstep", func(t *testing.T) {
+		a := NewCursor(NewIter(), []int{1, 3, 5})
+		b := NewCursor(NewIter(), [][]int{{2}, {4, 6}})
+		var res []interface{}
+		for a.Next() && b.Next() {
+			res = append(res, a.Pair().Val(), b.Pair().Val())
+		}
+		if exp := []interface{}{1, 2, 3, 4, 5, 6}; !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		c := NewCursor(NewIter(), []int{1, 2, 3})
+		if !c.Next() || c.Pair().Val() != 1 {
+			t.Fatalf("expected first element, got: %v", c.Pair())
+		}
+		c.Close()
+		if c.Next() {
+			t.Fatal("expected Next to return false once closed")
+		}
+		if c.Pair() != nil || c.Err() != nil {
+			t.Fatalf("expected nil Pair and Err, got: %v %v", c.Pair(), c.Err())
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		c := NewCursor(errIter{NewIter()}, map[string][]int{"a": {1}})
+		if c.Next() {
+			t.Fatalf("expected Next to return false, got: %v", c.Pair())
+		}
+		if err := c.Err(); err == nil || err.Error() != "iteration failed" {
+			t.Fatalf("expected iteration failed err, got: %v", err)
+		}
+		if c.Next() {
+			t.Fatal("expected Next to return false after an error")
+		}
+	})
+
+	t.Run("Cycles", func(t *testing.T) {
+		type testCursorNode struct{ Next *testCursorNode }
+		node := &testCursorNode{}
+		node.Next = node
+
+		c := NewCursor(NewIter(), node)
+		if !c.Next() {
+			t.Fatalf("expected a Pair, got err: %v", c.Err())
+		}
+		var ce *CycleError
+		if !errors.As(c.Pair().Err(), &ce) {
+			t.Fatalf("expected CycleError, got: %v", c.Pair().Err())
+		}
+		if c.Next() {
+			t.Fatalf("expected a single Pair, got: %v", c.Pair())
+		}
+	})
+}
@@ -0,0 +1,29 @@
+//go:build go1.23
+
+package iter_test
+
+import (
+	"fmt"
+
+	iter "github.com/cstockton/go-iter"
+)
+
+func ExampleSeq() {
+
+	v := []interface{}{"a", []string{"b", "c"}, "d"}
+
+	seq, errf := iter.Seq(v)
+	for el := range seq {
+		if el.Val() == "c" {
+			break
+		}
+		fmt.Printf("%v = %v\n", el.Path(), el.Val())
+	}
+	if err := errf(); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// [0] = a
+	// [1][0] = b
+}
@@ -148,3 +148,178 @@ func Example_recursion() {
 	//   Tail -> tail #1
 
 }
+
+func ExampleNewBFSWalker() {
+
+	v := []interface{}{[]interface{}{[]string{"c"}, "b"}, "a"}
+
+	w := iter.NewBFSWalker(iter.NewIter())
+	err := w.Walk(v, func(el iter.Pair) error {
+		fmt.Printf("%v at depth %d\n", el.Val(), el.Depth())
+		return nil
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// a at depth 1
+	// b at depth 2
+	// c at depth 3
+}
+
+func ExampleTraverse() {
+
+	type Package struct {
+		Name    string
+		Imports []string
+	}
+	pkg := Package{"iter", []string{"fmt", "reflect"}}
+
+	var depth int
+	name := func(el iter.Pair) string {
+		if sf, ok := el.Key().(reflect.StructField); ok {
+			return sf.Name
+		}
+		return fmt.Sprintf("%v", el.Key())
+	}
+	err := iter.Traverse(pkg, iter.WalkFuncs{
+		Enter: func(el iter.Pair) error {
+			fmt.Printf("%v%v {\n", strings.Repeat("  ", depth), name(el))
+			depth++
+			return nil
+		},
+		Leaf: func(el iter.Pair) error {
+			fmt.Printf("%v%v: %v\n", strings.Repeat("  ", depth), name(el), el.Val())
+			return nil
+		},
+		Exit: func(el iter.Pair) error {
+			depth--
+			fmt.Printf("%v}\n", strings.Repeat("  ", depth))
+			return nil
+		},
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// <nil> {
+	//   Name: iter
+	//   Imports {
+	//     0: fmt
+	//     1: reflect
+	//   }
+	// }
+}
+
+func ExamplePath() {
+
+	type Package struct {
+		Name string
+		Meta map[string]string
+	}
+	v := struct{ Packages []Package }{[]Package{
+		{"io", nil},
+		{"fmt", map[string]string{"owner": "gopher"}},
+	}}
+
+	iter.Walk(v, func(el iter.Pair) error {
+		fmt.Printf("%v = %v\n", el.Path(), el.Val())
+		return nil
+	})
+
+	// Output:
+	// .Packages[0].Name = io
+	// .Packages[1].Name = fmt
+	// .Packages[1].Meta["owner"] = gopher
+}
+
+func ExampleResolvePointer() {
+
+	type Package struct {
+		Name string            `json:"name"`
+		Meta map[string]string `json:"meta"`
+	}
+	v := struct {
+		Packages []Package `json:"packages"`
+	}{[]Package{{"fmt", map[string]string{"owner": "gopher"}}}}
+
+	iter.Walk(v, func(el iter.Pair) error {
+		ptr, _ := el.Path().JSONPointer()
+		res, err := iter.ResolvePointer(v, ptr)
+		fmt.Println(ptr, res, err)
+		return nil
+	})
+
+	// Output:
+	// /packages/0/name fmt <nil>
+	// /packages/0/meta/owner gopher <nil>
+}
+
+func ExampleLookup() {
+
+	type Container struct {
+		Name string
+		Env  map[string]string
+	}
+	v := struct{ Containers []*Container }{[]*Container{
+		{"web", map[string]string{"HOME": "/srv"}},
+	}}
+
+	home, err := iter.Lookup(v, `Containers[0].Env["HOME"]`)
+	fmt.Println(home, err)
+
+	_, err = iter.Lookup(v, `Containers[1].Name`)
+	fmt.Println(err)
+
+	// Output:
+	// /srv <nil>
+	// lookup .Containers[1].Name: at .Containers[1]: index 1 out of range [0:1]
+}
+
+func Example_set() {
+
+	type Config struct {
+		User     string
+		Password string
+		Tokens   map[string]string
+	}
+	cfg := &Config{"gopher", "hunter2", map[string]string{"api": "abc123"}}
+
+	// Walking a pointer allows values to be modified through Set.
+	err := iter.Walk(cfg, func(el iter.Pair) error {
+		if el.Path().String() == ".User" {
+			return nil
+		}
+		return el.Set("<redacted>")
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(cfg.User, cfg.Password, cfg.Tokens)
+
+	// Output:
+	// gopher <redacted> map[api:<redacted>]
+}
+
+func ExampleIter_sortMapKeys() {
+
+	m := map[string][]int{"b": {3, 4}, "a": {1, 2}, "c": {5}}
+
+	w := iter.NewWalker(&iter.Iter{SortMapKeys: true})
+	err := w.Walk(m, func(el iter.Pair) error {
+		fmt.Printf("%v = %v\n", el.Path(), el.Val())
+		return nil
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// ["a"][0] = 1
+	// ["a"][1] = 2
+	// ["b"][0] = 3
+	// ["b"][1] = 4
+	// ["c"][0] = 5
+}
@@ -2,13 +2,15 @@
 package iter
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"sort"
 )
 
 var (
 	defaultIter   = Iter{}
-	defaultWalker = dfsWalker{Iterator: &defaultIter}
+	defaultWalker = dfsWalker{newWalker(&defaultIter, nil)}
 )
 
 // Iterator is a basic interface for iterating elements of a structured type. It
@@ -23,6 +25,14 @@ type Iterator interface {
 		f func(field reflect.StructField, val reflect.Value) error) error
 }
 
+// A ContextIterator is an Iterator that can interrupt channel receives, which
+// may otherwise block forever, once the given context is done.
+type ContextIterator interface {
+	Iterator
+	IterChanContext(ctx context.Context, val reflect.Value,
+		f func(seq int, ch reflect.Value) error) error
+}
+
 // NewIter returns a new Iter.
 func NewIter() Iterator {
 	return &Iter{}
@@ -39,16 +49,37 @@ func NewRecoverIter(it Iterator) Iterator {
 // in a iter.NewrecoverFnIter(Iter{}). Performance cost for Visiting slices is
 // negligible relative to iteration using range. About 3x slower for slices with
 // less than 1k elements, for slices  with more than 2k elements it will be
-// around 2x as slow. Iterating maps is only 2-3 times slower for small maps of
-// 100-1k elements. When you start to go above that its deficiencies will start
-// to take a linear tax on runtime proportianite to the number of elements. It's
-// roughly 100x slower at 32k elements. This is because it loads all map keys
-// into memory (implementation of reflect.MapKeys).
+// around 2x as slow. Maps are iterated using reflect.Value.MapRange, so unlike
+// reflect.MapKeys no keys are loaded into memory and large maps walk in
+// constant extra memory. Each element still costs an allocation for its key and
+// value, which ReuseMapValues avoids, making it 2-3 times faster for maps of 2k
+// to 1M elements. The fields of each struct type, after applying the exclusion
+// options, are resolved once and cached for the life of the program.
 type Iter struct {
 	ChanRecv          bool
 	ChanBlock         bool
 	ExcludeAnonymous  bool
 	ExcludeUnexported bool
+
+	// SortMapKeys causes IterMap to visit keys in a stable sorted order rather
+	// than the random order of the Go runtime. Keys are ordered the same as
+	// package fmt orders them when printing maps, see compareValues.
+	SortMapKeys bool
+
+	// ReuseMapValues causes IterMap to store each key and value in the same
+	// pair of reflect.Values, avoiding an allocation per element. They are
+	// only valid until f returns, so this must not be used when the Values,
+	// or the Pairs a Walker creates from them, are retained. Maps retrieved
+	// through unexported fields are iterated as if this were not set.
+	ReuseMapValues bool
+
+	// AllowUnexported uses package unsafe to give IterStruct readable values
+	// for unexported fields, so Walkers visit their contents like exported
+	// ones. This bypasses the protections of package reflect and is intended
+	// for debugging dumps and deep equality checks. If the struct is not
+	// addressable the unexported fields are read from a copy of it and can not
+	// be set, otherwise setting them modifies the original value.
+	AllowUnexported bool
 }
 
 // IterMap will visit each key and value of a map.
@@ -58,8 +89,25 @@ func (it Iter) IterMap(val reflect.Value, f func(
 	if reflect.Map != kind {
 		return fmt.Errorf("expected map kind, not %s", kind)
 	}
-	for _, key := range val.MapKeys() {
-		element := val.MapIndex(key)
+	if it.SortMapKeys {
+		return it.iterMapSorted(val, f)
+	}
+
+	mi := val.MapRange()
+	if !it.ReuseMapValues || !val.CanInterface() {
+		for mi.Next() {
+			if err := f(mi.Key(), mi.Value()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	typ := val.Type()
+	key, element := reflect.New(typ.Key()).Elem(), reflect.New(typ.Elem()).Elem()
+	for mi.Next() {
+		key.SetIterKey(mi)
+		element.SetIterValue(mi)
 		if err := f(key, element); err != nil {
 			return err
 		}
@@ -67,6 +115,44 @@ func (it Iter) IterMap(val reflect.Value, f func(
 	return nil
 }
 
+// iterMapSorted visits each key and value of a map in the order given by
+// compareValues. Values are retrieved alongside keys since keys such as NaN
+// can not be used to index the map.
+func (it Iter) iterMapSorted(val reflect.Value, f func(
+	key, val reflect.Value) error) error {
+	keys := make([]reflect.Value, 0, val.Len())
+	elements := make([]reflect.Value, 0, val.Len())
+	for mi := val.MapRange(); mi.Next(); {
+		keys = append(keys, mi.Key())
+		elements = append(elements, mi.Value())
+	}
+	sort.Stable(sortedMap{keys, elements})
+
+	for i, key := range keys {
+		if err := f(key, elements[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type sortedMap struct {
+	keys, elements []reflect.Value
+}
+
+func (m sortedMap) Len() int {
+	return len(m.keys)
+}
+
+func (m sortedMap) Less(i, j int) bool {
+	return compareValues(m.keys[i], m.keys[j]) < 0
+}
+
+func (m sortedMap) Swap(i, j int) {
+	m.keys[i], m.keys[j] = m.keys[j], m.keys[i]
+	m.elements[i], m.elements[j] = m.elements[j], m.elements[i]
+}
+
 // IterSlice will visit each element of an array or slice. Extending the length
 // of an Array or Slice during iteration may panic.
 func (it Iter) IterSlice(val reflect.Value, f func(
@@ -93,16 +179,27 @@ func (it Iter) IterStruct(val reflect.Value, f func(
 		return fmt.Errorf("expected struct kind, not %s", kind)
 	}
 	typ := val.Type()
-	for i := 0; i < val.NumField(); i++ {
-		field := typ.Field(i)
-		if field.Anonymous && it.ExcludeAnonymous {
-			continue
-		}
-		if len(field.PkgPath) > 0 && it.ExcludeUnexported {
-			continue
-		}
+	var cpy reflect.Value
+	if it.AllowUnexported && !val.CanAddr() && val.CanInterface() {
+		cpy = reflect.New(typ).Elem()
+		cpy.Set(val)
+	}
+	tp := planOf(typ)
+	filter := planFilter(it.ExcludeAnonymous, it.ExcludeUnexported)
+	for _, i := range tp.indexes[filter] {
+		field := &tp.fields[i].field
 		element := val.Field(i)
-		if err := f(field, element); err != nil {
+		if len(field.PkgPath) > 0 && it.AllowUnexported {
+			switch {
+			case element.CanAddr():
+				element = unexportedValue(element)
+			case cpy.IsValid():
+				// Copied again so setting it can't appear to modify val.
+				element = unexportedValue(cpy.Field(i))
+				element = valueOf(element.Interface(), element.Type())
+			}
+		}
+		if err := f(*field, element); err != nil {
 			return err
 		}
 	}
@@ -117,6 +214,13 @@ func (it Iter) IterStruct(val reflect.Value, f func(
 // counter for this iterations receives is returned for parity with structured
 // types.
 func (it Iter) IterChan(val reflect.Value, f func(
+	seq int, recv reflect.Value) error) error {
+	return it.IterChanContext(context.Background(), val, f)
+}
+
+// IterChanContext is like IterChan, but returns ctx.Err() once the context is
+// done, including while blocked on a receive when ChanBlock is set.
+func (it Iter) IterChanContext(ctx context.Context, val reflect.Value, f func(
 	seq int, recv reflect.Value) error) error {
 	if !it.ChanRecv {
 		return nil
@@ -125,16 +229,38 @@ func (it Iter) IterChan(val reflect.Value, f func(
 	if reflect.Chan != kind {
 		return fmt.Errorf("expected chan kind, not %s", kind)
 	}
+
 	var (
-		recv reflect.Value
-		ok   bool
+		recv  reflect.Value
+		ok    bool
+		cases []reflect.SelectCase
 	)
+	done := ctx.Done()
+	if it.ChanBlock && done != nil {
+		cases = []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: val},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)},
+		}
+	}
 	i := -1
 	for {
-		if it.ChanBlock {
-			recv, ok = val.Recv()
-		} else {
+		if done != nil {
+			select {
+			case <-done:
+				return ctx.Err()
+			default:
+			}
+		}
+		switch {
+		case !it.ChanBlock:
 			recv, ok = val.TryRecv()
+		case done == nil:
+			recv, ok = val.Recv()
+		default:
+			var chosen int
+			if chosen, recv, ok = reflect.Select(cases); chosen == 1 {
+				return ctx.Err()
+			}
 		}
 		if !ok {
 			return nil
@@ -177,3 +303,13 @@ func (it recoverIter) IterChan(val reflect.Value, f func(
 		return it.Iterator.IterChan(val, f)
 	})
 }
+
+func (it recoverIter) IterChanContext(ctx context.Context, val reflect.Value,
+	f func(seq int, recv reflect.Value) error) (err error) {
+	return recoverFn(func() error {
+		if ci, ok := it.Iterator.(ContextIterator); ok {
+			return ci.IterChanContext(ctx, val, f)
+		}
+		return it.Iterator.IterChan(val, f)
+	})
+}
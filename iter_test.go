@@ -2,11 +2,15 @@ package iter
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type invalidIter struct {
@@ -31,6 +35,16 @@ func (it invalidIter) IterChan(val reflect.Value, f func(seq int, recv reflect.V
 	return f(0, it.zeroValue)
 }
 
+// errIter fails to iterate any slice.
+type errIter struct {
+	Iterator
+}
+
+func (it errIter) IterSlice(val reflect.Value,
+	f func(idx int, val reflect.Value) error) error {
+	return errors.New("iteration failed")
+}
+
 func tchkstr(t testing.TB, err error, errStr string) error {
 	if err != nil {
 		if len(errStr) == 0 {
@@ -615,3 +629,385 @@ func TestIterChan(t *testing.T) {
 		})
 	}
 }
+
+func TestIterStructAllowUnexported(t *testing.T) {
+	type testAllowUnexportedInner struct {
+		a int
+		B string
+	}
+	type testAllowUnexported struct {
+		A     int
+		b     string
+		inner testAllowUnexportedInner
+		m     map[string]int
+	}
+	give := testAllowUnexported{1, "b", testAllowUnexportedInner{2, "c"}, map[string]int{"d": 3}}
+	it := &Iter{AllowUnexported: true}
+
+	t.Run("IterStruct", func(t *testing.T) {
+		for _, val := range []reflect.Value{
+			reflect.ValueOf(give), reflect.ValueOf(&give).Elem()} {
+			var res []interface{}
+			addr := val.CanAddr()
+			err := it.IterStruct(val, func(field reflect.StructField, val reflect.Value) error {
+				if !val.CanInterface() {
+					t.Fatalf("cant interface val in result: field(%v) val(%v)", field, val)
+				}
+				if val.CanSet() != addr {
+					t.Fatalf("expected CanSet to be %v for field(%v)", addr, field)
+				}
+				res = append(res, val.Interface())
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("expected nil err, got: %v", err)
+			}
+			exp := []interface{}{give.A, give.b, give.inner, give.m}
+			if !reflect.DeepEqual(exp, res) {
+				t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+			}
+		}
+	})
+
+	t.Run("Walk", func(t *testing.T) {
+		cpy := give
+		res := make(map[string]interface{})
+		err := NewWalker(it).Walk(&cpy, func(el Pair) error {
+			res[el.Path().String()] = el.Val()
+			return el.Set(el.Val())
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		exp := map[string]interface{}{
+			".A": 1, ".b": "b", ".inner.a": 2, ".inner.B": "c", `.m["d"]`: 3}
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		cpy := give
+		err := NewWalker(it).Walk(&cpy, func(el Pair) error {
+			if el.Path().String() == ".inner.a" {
+				return el.Set(5)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if cpy.inner.a != 5 || give.inner.a != 2 {
+			t.Fatalf("expected Set to modify only the walked value, got: %v", cpy.inner.a)
+		}
+	})
+
+	t.Run("SetCopy", func(t *testing.T) {
+		cpy := give
+		m := map[string]testAllowUnexported{"k": give}
+		for _, v := range []interface{}{cpy, &m} {
+			var n int
+			err := NewWalker(it).Walk(v, func(el Pair) error {
+				if _, ok := el.Key().(reflect.StructField); !ok {
+					return nil
+				}
+				n++
+				err := el.Set(el.Val())
+				return tchkstr(t, err, `value is not addressable`)
+			})
+			if err != nil {
+				t.Fatalf("expected nil err, got: %v", err)
+			}
+			if n != 4 {
+				t.Fatalf("expected 4 fields, got: %v", n)
+			}
+		}
+	})
+}
+
+func TestIterChanContext(t *testing.T) {
+	var _ ContextIterator = Iter{}
+	var _ ContextIterator = (*Iter)(nil)
+	var _ ContextIterator = recoverIter{}
+
+	its := map[string]ContextIterator{
+		"Iter":             &Iter{ChanRecv: true},
+		"IterBlock":        &Iter{ChanRecv: true, ChanBlock: true},
+		"RecoverIter":      &recoverIter{&Iter{ChanRecv: true}},
+		"RecoverIterBlock": &recoverIter{&Iter{ChanRecv: true, ChanBlock: true}},
+	}
+	for name, it := range its {
+		it := it
+		t.Run(name, func(t *testing.T) {
+			t.Run("Canceled", func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				ch := make(chan int, 3)
+				ch <- 1
+				ch <- 2
+				ch <- 3
+
+				i := 0
+				err := it.IterChanContext(ctx, reflect.ValueOf(ch), func(seq int, recv reflect.Value) error {
+					i++
+					cancel()
+					return nil
+				})
+				if err != context.Canceled {
+					t.Fatalf("expected err %v, got: %v", context.Canceled, err)
+				}
+				if i != 1 {
+					t.Fatalf("expected exactly 1 receive, got: %v", i)
+				}
+			})
+			t.Run("Background", func(t *testing.T) {
+				ch := make(chan int, 2)
+				ch <- 1
+				ch <- 2
+				close(ch)
+
+				i := 0
+				err := it.IterChanContext(context.Background(), reflect.ValueOf(ch), func(seq int, recv reflect.Value) error {
+					i++
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("expected nil err, got: %v", err)
+				}
+				if i != 2 {
+					t.Fatalf("expected exactly 2 receives, got: %v", i)
+				}
+			})
+		})
+	}
+
+	t.Run("Blocked", func(t *testing.T) {
+		it := &Iter{ChanRecv: true, ChanBlock: true}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+
+		ch := make(chan int)
+		err := it.IterChanContext(ctx, reflect.ValueOf(ch), func(seq int, recv reflect.Value) error {
+			t.Fatal("expected no receives")
+			return nil
+		})
+		if err != context.DeadlineExceeded {
+			t.Fatalf("expected err %v, got: %v", context.DeadlineExceeded, err)
+		}
+	})
+
+	t.Run("RecoverFallback", func(t *testing.T) {
+		it := &recoverIter{invalidIter{}}
+		err := it.IterChanContext(context.Background(), reflect.ValueOf(make(chan int)), func(seq int, recv reflect.Value) error {
+			if recv.IsValid() {
+				t.Fatal("expected invalidIter to give zero value")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+	})
+}
+
+func TestIterMapSortMapKeys(t *testing.T) {
+	type testSortKey struct {
+		A int
+		B string
+	}
+	nan := math.NaN()
+	x, y := 1, 2
+	ch1, ch2 := make(chan int), make(chan int)
+	tests := map[string]interface{}{
+		"Ints":    map[int]string{3: "c", -1: "a", 2: "b", 0: "z"},
+		"Int8s":   map[int8]int{3: 1, -128: 2, 127: 3},
+		"Uints":   map[uint64]int{3: 1, 1 << 63: 2, 0: 3},
+		"Strings": map[string]int{"b": 1, "a": 2, "": 3, "ab": 4},
+		"Bools":   map[bool]int{true: 1, false: 2},
+		"Floats":  map[float64]int{2.5: 1, nan: 2, -1: 3, math.Inf(1): 4, nan: 2},
+		"Complex": map[complex128]int{complex(1, 2): 1, complex(1, 1): 2, complex(0, 3): 3},
+		"Structs": map[testSortKey]int{{2, "a"}: 1, {1, "b"}: 2, {1, "a"}: 3},
+		"Arrays":  map[[2]int]int{{2, 1}: 1, {1, 2}: 2, {1, 1}: 3},
+		"Ptrs":    map[*int]int{&x: 1, &y: 2, nil: 3},
+		"Chans":   map[chan int]int{ch1: 1, ch2: 2, nil: 3},
+		"Ifaces": map[interface{}]int{
+			"b": 1, 2: 2, "a": 3, 1: 4, nil: 5, 1.5: 6, testSortKey{1, "a"}: 7},
+	}
+	for name, give := range tests {
+		give := give
+		t.Run(name, func(t *testing.T) {
+			val := reflect.ValueOf(give)
+			for _, it := range []Iterator{
+				&Iter{SortMapKeys: true}, &recoverIter{&Iter{SortMapKeys: true}}} {
+				for i := 0; i < 4; i++ {
+					var buf bytes.Buffer
+					err := it.IterMap(val, func(key, val reflect.Value) error {
+						if buf.Len() > 0 {
+							buf.WriteByte(' ')
+						}
+						fmt.Fprintf(&buf, "%v:%v", key, val)
+						return nil
+					})
+					if err != nil {
+						t.Fatalf("expected nil err, got: %v", err)
+					}
+					exp := fmt.Sprintf("%v", give)
+					if got := "map[" + buf.String() + "]"; exp != got {
+						t.Fatalf("expected fmt ordering:\n  exp: %v\n  got: %v", exp, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestIterMapReuseMapValues(t *testing.T) {
+	give := map[string]int{"a": 1, "b": 2, "c": 3}
+
+	t.Run("IterMap", func(t *testing.T) {
+		for _, it := range []Iterator{
+			&Iter{ReuseMapValues: true}, &recoverIter{&Iter{ReuseMapValues: true}}} {
+			res := make(map[string]int)
+			var keys []reflect.Value
+			err := it.IterMap(reflect.ValueOf(give), func(key, val reflect.Value) error {
+				res[key.String()] = int(val.Int())
+				keys = append(keys, key)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("expected nil err, got: %v", err)
+			}
+			if !reflect.DeepEqual(give, res) {
+				t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", give, res)
+			}
+			if keys[0].String() != keys[2].String() {
+				t.Fatalf("expected keys to share storage, got: %v", keys)
+			}
+		}
+	})
+
+	t.Run("Unexported", func(t *testing.T) {
+		v := reflect.ValueOf(struct{ m map[string]int }{give}).Field(0)
+		i := 0
+		err := (&Iter{ReuseMapValues: true}).IterMap(v, func(key, val reflect.Value) error {
+			i++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if i != len(give) {
+			t.Fatalf("expected %v elements, got: %v", len(give), i)
+		}
+	})
+
+	t.Run("NaN", func(t *testing.T) {
+		m := map[float64]int{math.NaN(): 1, math.NaN(): 2}
+		for _, it := range []Iterator{&Iter{}, &Iter{ReuseMapValues: true}} {
+			sum := 0
+			err := it.IterMap(reflect.ValueOf(m), func(key, val reflect.Value) error {
+				sum += int(val.Int())
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("expected nil err, got: %v", err)
+			}
+			if sum != 3 {
+				t.Fatalf("expected values of NaN keys to be visited, got: %v", sum)
+			}
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		m := map[string]int{"a": 1, "b": 2, "c": 3}
+		err := NewWalker(&Iter{ReuseMapValues: true}).Walk(m, func(el Pair) error {
+			return el.Set(el.Val().(int) * 10)
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if exp := map[string]int{"a": 10, "b": 20, "c": 30}; !reflect.DeepEqual(exp, m) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, m)
+		}
+	})
+
+	t.Run("SetElements", func(t *testing.T) {
+		type testReuseSet struct{ A int }
+		for _, it := range []Iterator{&Iter{}, &Iter{ReuseMapValues: true}} {
+			m := map[string]testReuseSet{"a": {1}}
+			var res []error
+			err := NewWalker(it).Walk(&m, func(el Pair) error {
+				if _, ok := el.Key().(reflect.StructField); ok {
+					res = append(res, el.Set(42))
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("expected nil err, got: %v", err)
+			}
+			if len(res) != 1 {
+				t.Fatalf("expected 1 field to be visited, got: %v", res)
+			}
+			if err := tchkstr(t, res[0], `value is not addressable`); err != nil {
+				t.Fatal(err)
+			}
+			if exp := map[string]testReuseSet{"a": {1}}; !reflect.DeepEqual(exp, m) {
+				t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, m)
+			}
+		}
+	})
+
+	t.Run("Walkers", func(t *testing.T) {
+		m := map[string]interface{}{
+			"a": []int{1}, "b": []int{2}, "c": []int{3}, "d": nil, "e": 4}
+		exp := map[string]interface{}{
+			`["a"]`: []int{1}, `["a"][0]`: 1, `["b"]`: []int{2}, `["b"][0]`: 2,
+			`["c"]`: []int{3}, `["c"][0]`: 3, `["d"]`: nil, `["e"]`: 4,
+		}
+		kinds := func(w Walker) map[string]reflect.Kind {
+			var mu sync.Mutex
+			res := make(map[string]reflect.Kind)
+			err := w.Walk(m, func(el Pair) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if el.Parent() != nil {
+					res[el.Path().String()] = el.Kind()
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("expected nil err, got: %v", err)
+			}
+			return res
+		}
+		expKinds := kinds(NewWalker(&Iter{}, VisitContainers()))
+
+		it := &Iter{ReuseMapValues: true}
+		walkers := map[string]Walker{
+			"DFS":       NewWalker(it, VisitContainers()),
+			"BFS":       NewBFSWalker(it, VisitContainers()),
+			"Iterative": NewWalker(it, VisitContainers(), Iterative()),
+			"Parallel":  NewParallelWalker(it, 4, VisitContainers()),
+		}
+		for name, w := range walkers {
+			var mu sync.Mutex
+			res := make(map[string]interface{})
+			err := w.Walk(m, func(el Pair) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if el.Parent() != nil {
+					res[el.Path().String()] = el.Val()
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("%v: expected nil err, got: %v", name, err)
+			}
+			if !reflect.DeepEqual(exp, res) {
+				t.Fatalf("%v: DeepEqual failed:\n  exp: %#v\n  got: %#v", name, exp, res)
+			}
+			if got := kinds(w); !reflect.DeepEqual(expKinds, got) {
+				t.Fatalf("%v: DeepEqual failed:\n  exp: %#v\n  got: %#v", name, expKinds, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package iter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Lookup returns the value located at the path expression within value,
+// without visiting any unrelated elements. Pointers and interfaces are followed
+// as Walk does. See ParsePath for the syntax of expr, which is the same as
+// Path.String() returns for paths whose map keys are strings, bools or numbers,
+// for example:
+//
+//	Spec.Containers[0].Env["HOME"]
+func Lookup(value interface{}, expr string) (interface{}, error) {
+	p, err := ParsePath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return LookupPath(value, p)
+}
+
+// LookupPath returns the value located at the given Path within value. Field
+// steps are matched by name, Index steps may also be used to access maps with
+// integer or floating point keys and MapKey steps are converted to the maps key
+// type when they share the same kind, or are both integers or floating point
+// numbers. ChanSeq steps can not be looked up since receiving would consume the
+// element.
+func LookupPath(value interface{}, p Path) (interface{}, error) {
+	r := resolver{Iterator: &defaultIter}
+	cur := value
+	for i, s := range p {
+		next, err := r.step(reflect.ValueOf(indirect(cur)), s)
+		if err != nil {
+			return nil, fmt.Errorf("lookup %v: at %v: %v", p, p[:i+1], err)
+		}
+		cur = next.Interface()
+	}
+	return cur, nil
+}
+
+// ParsePath parses a path expression into a Path. An expression is a series of
+// steps, a period followed by a field name, or brackets enclosing an integer
+// index, a receive sequence such as `[<-0]` or a map key that is a quoted
+// string, a bool or a floating point number. The leading period may be
+// omitted, so both `.Spec.Name` and `Spec.Name` are valid. The empty string is
+// the root path.
+//
+// Paths whose map keys are strings, bools or numbers are parsed back from
+// Path.String() unchanged, except that integer map keys and floating point keys
+// with integer values become an Index, which LookupPath accepts for maps.
+func ParsePath(expr string) (Path, error) {
+	var p Path
+	s := expr
+	if len(s) > 0 && s[0] != '.' && s[0] != '[' {
+		s = "." + s
+	}
+	for len(s) > 0 {
+		var (
+			step Step
+			n    int
+			err  error
+		)
+		switch s[0] {
+		case '.':
+			step, n, err = parseField(s)
+		case '[':
+			step, n, err = parseBracket(s)
+		default:
+			err = fmt.Errorf("unexpected %q", s[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("path %q: offset %d: %v",
+				expr, len(expr)-len(s), err)
+		}
+		p = append(p, step)
+		s = s[n:]
+	}
+	return p, nil
+}
+
+func parseField(s string) (Step, int, error) {
+	n := 1
+	for i, r := range s[1:] {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		n = i + 1 + len(string(r))
+	}
+	if n == 1 {
+		return nil, 0, errors.New("expected field name")
+	}
+	return Field(reflect.StructField{Name: s[1:n]}), n, nil
+}
+
+func parseBracket(s string) (Step, int, error) {
+	if len(s) > 1 && s[1] == '"' {
+		lit, err := strconv.QuotedPrefix(s[1:])
+		if err != nil {
+			return nil, 0, errors.New("invalid quoted map key")
+		}
+		n := 1 + len(lit)
+		if n >= len(s) || s[n] != ']' {
+			return nil, 0, errors.New("expected ']'")
+		}
+		key, err := strconv.Unquote(lit)
+		if err != nil {
+			return nil, 0, err
+		}
+		return MapKey{key}, n + 1, nil
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return nil, 0, errors.New("expected ']'")
+	}
+	lit := s[1:end]
+	if strings.HasPrefix(lit, "<-") {
+		seq, err := strconv.Atoi(lit[2:])
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid receive sequence %q", lit)
+		}
+		return ChanSeq(seq), end + 1, nil
+	}
+	if lit == "true" || lit == "false" {
+		return MapKey{lit == "true"}, end + 1, nil
+	}
+	if idx, err := strconv.Atoi(lit); err == nil {
+		return Index(idx), end + 1, nil
+	}
+
+	// Integers beyond the range of int can only be the key of a map.
+	if u, err := strconv.ParseUint(lit, 10, 64); err == nil {
+		return MapKey{u}, end + 1, nil
+	}
+	if f, err := strconv.ParseFloat(lit, 64); err == nil {
+		return MapKey{f}, end + 1, nil
+	}
+	return nil, 0, fmt.Errorf("invalid index %q", lit)
+}
+
+func (r resolver) step(in reflect.Value, s Step) (reflect.Value, error) {
+	kind := in.Kind()
+	switch T := s.(type) {
+	case Field:
+		if kind != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("expected struct kind, not %s", kind)
+		}
+		return r.field(in, func(sf reflect.StructField) bool {
+			return sf.Name == T.Name
+		})
+	case Index:
+		switch kind {
+		case reflect.Slice, reflect.Array:
+			return r.index(in, int(T))
+		case reflect.Map:
+			return r.mapIndex(in, int(T))
+		}
+		return reflect.Value{}, fmt.Errorf(
+			"expected array, slice or map kind, not %s", kind)
+	case MapKey:
+		if kind != reflect.Map {
+			return reflect.Value{}, fmt.Errorf("expected map kind, not %s", kind)
+		}
+		return r.mapIndex(in, T.Key)
+	case ChanSeq:
+		return reflect.Value{}, errors.New("channel elements can not be looked up")
+	default:
+		return reflect.Value{}, fmt.Errorf("unknown step %T", s)
+	}
+}
+
+// mapIndex returns the element of the map in located at key, which is
+// converted to the maps key type if it is of the same kind or both are numbers,
+// as long as the key is within the range of the type. Unlike other steps this
+// does not use the Iterator, since that would visit each key.
+func (r resolver) mapIndex(in reflect.Value,
+	key interface{}) (reflect.Value, error) {
+	typ := in.Type().Key()
+	kv := reflect.ValueOf(key)
+	switch {
+	case !kv.IsValid():
+		kv = reflect.Zero(typ)
+	case kv.Type().AssignableTo(typ):
+	case kv.Kind() == typ.Kind(), isIntKind(kv.Kind()) && isIntKind(typ.Kind()),
+		kv.Kind() == reflect.Int && isFloatKind(typ.Kind()),
+		isFloatKind(kv.Kind()) && isFloatKind(typ.Kind()):
+		if overflowsKey(kv, typ) {
+			return reflect.Value{}, fmt.Errorf("key %v out of range for %s",
+				key, typ)
+		}
+		kv = kv.Convert(typ)
+	default:
+		return reflect.Value{}, fmt.Errorf("key of type %s can not be used as %s",
+			kv.Type(), typ)
+	}
+
+	res := in.MapIndex(kv)
+	if !res.IsValid() {
+		return reflect.Value{}, errors.New("no such key")
+	}
+	if !res.CanInterface() {
+		return reflect.Value{}, errors.New("key is not accessible")
+	}
+	return res, nil
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// overflowsKey reports if converting the number kv to typ would not preserve
+// its value, such as 300 as an uint8 or -1 as an uint.
+func overflowsKey(kv reflect.Value, typ reflect.Type) bool {
+	zero := reflect.Zero(typ)
+	from, to := kv.Kind(), typ.Kind()
+	switch {
+	case isSignedKind(from) && isSignedKind(to):
+		return zero.OverflowInt(kv.Int())
+	case isSignedKind(from) && isIntKind(to):
+		return kv.Int() < 0 || zero.OverflowUint(uint64(kv.Int()))
+	case isIntKind(from) && isSignedKind(to):
+		return kv.Uint() > math.MaxInt64 || zero.OverflowInt(int64(kv.Uint()))
+	case isIntKind(from) && isIntKind(to):
+		return zero.OverflowUint(kv.Uint())
+	case isFloatKind(from) && isFloatKind(to):
+		return zero.OverflowFloat(kv.Float())
+	}
+	return false
+}
+
+func isSignedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
@@ -0,0 +1,200 @@
+package iter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	fld := func(name string) Step {
+		return Field(reflect.StructField{Name: name})
+	}
+	tests := []struct {
+		expr   string
+		exp    Path
+		errStr string
+	}{
+		{``, nil, ``},
+		{`Spec`, Path{fld("Spec")}, ``},
+		{`.Spec`, Path{fld("Spec")}, ``},
+		{`Spec.Containers[0].Env["HOME"]`,
+			Path{fld("Spec"), fld("Containers"), Index(0), fld("Env"), MapKey{"HOME"}}, ``},
+		{`[1][-2]["a\"]b"]`, Path{Index(1), Index(-2), MapKey{`a"]b`}}, ``},
+		{`.Ünicode_1`, Path{fld("Ünicode_1")}, ``},
+		{`Spec.`, nil, `offset 4: expected field name`},
+		{`Spec..Name`, nil, `offset 4: expected field name`},
+		{`[0`, nil, `expected ']'`},
+		{`["a"`, nil, `expected ']'`},
+		{`["a]`, nil, `invalid quoted map key`},
+		{`[a]`, nil, `invalid index "a"`},
+		{`[<-3][true][false][1.5][-1e+21]`,
+			Path{ChanSeq(3), MapKey{true}, MapKey{false}, MapKey{1.5}, MapKey{-1e+21}}, ``},
+		{`[18446744073709551615]`, Path{MapKey{uint64(18446744073709551615)}}, ``},
+		{`[<-a]`, nil, `invalid receive sequence "<-a"`},
+		{`[True]`, nil, `invalid index "True"`},
+		{`.a-b`, nil, `offset 2: unexpected '-'`},
+	}
+	for _, tc := range tests {
+		got, err := ParsePath(tc.expr)
+		if err := tchkstr(t, err, tc.errStr); err != nil {
+			t.Errorf("%v: %v", tc.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(tc.exp, got) {
+			t.Errorf("DeepEqual failed for %v:\n  exp: %#v\n  got: %#v", tc.expr, tc.exp, got)
+		}
+	}
+}
+
+func TestParsePathString(t *testing.T) {
+	fld := func(name string) Step {
+		return Field(reflect.StructField{Name: name})
+	}
+	tests := []Path{
+		nil,
+		{fld("Spec"), fld("Containers"), Index(0), fld("Env"), MapKey{"HOME"}},
+		{MapKey{`a"]b`}, MapKey{""}, Index(-1)},
+		{MapKey{true}, MapKey{false}},
+		{MapKey{1.5}, MapKey{-0.25}, MapKey{1e+21}},
+		{fld("Ch"), ChanSeq(0), ChanSeq(12)},
+		{MapKey{uint64(1<<64 - 1)}},
+	}
+	for _, exp := range tests {
+		got, err := ParsePath(exp.String())
+		if err != nil {
+			t.Errorf("%v: expected nil err, got: %v", exp, err)
+			continue
+		}
+		if !reflect.DeepEqual(exp, got) {
+			t.Errorf("DeepEqual failed for %v:\n  exp: %#v\n  got: %#v", exp, exp, got)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	type testLookupID int
+	type testLookupContainer struct {
+		Name string
+		Env  map[string]string
+		env  string
+	}
+	type testLookupSpec struct {
+		Containers []*testLookupContainer
+		IDs        map[testLookupID]string
+		Any        interface{}
+		Floats     map[float64]string
+		Float32s   map[float32]string
+		Flags      map[bool]int
+	}
+	give := &struct {
+		Spec *testLookupSpec
+	}{&testLookupSpec{
+		Containers: []*testLookupContainer{
+			{Name: "a", Env: map[string]string{"HOME": "/root"}, env: "x"},
+		},
+		IDs: map[testLookupID]string{2: "b"},
+		Any: map[string]interface{}{"k": []int{1, 2}},
+
+		Floats:   map[float64]string{1.5: "c", 2: "d"},
+		Float32s: map[float32]string{1.5: "e", 0.1: "f"},
+		Flags:  map[bool]int{true: 1},
+	}}
+
+	tests := []struct {
+		expr   string
+		exp    interface{}
+		errStr string
+	}{
+		{``, give, ``},
+		{`Spec.Containers[0].Env["HOME"]`, "/root", ``},
+		{`.Spec.Containers[0].Name`, "a", ``},
+		{`Spec.Containers[0]`, give.Spec.Containers[0], ``},
+		{`Spec.IDs[2]`, "b", ``},
+		{`Spec.Any["k"][1]`, 2, ``},
+		{`Spec.Floats[1.5]`, "c", ``},
+		{`Spec.Floats[2]`, "d", ``},
+		{`Spec.Float32s[1.5]`, "e", ``},
+		{`Spec.Float32s[0.1]`, "f", ``},
+		{`Spec.Float32s[1e+300]`, nil, `key 1e+300 out of range for float32`},
+		{`Spec.Flags[true]`, 1, ``},
+		{`Spec.Flags[false]`, nil, `no such key`},
+		{`Spec.Containers[1]`, nil, `at .Spec.Containers[1]: index 1 out of range [0:1]`},
+		{`Spec.Containers[0].env`, nil, `no such field`},
+		{`Spec.Nope`, nil, `no such field`},
+		{`Spec.IDs[3]`, nil, `no such key`},
+		{`Spec.IDs["3"]`, nil, `key of type string can not be used as iter.testLookupID`},
+		{`Spec["a"]`, nil, `expected map kind, not struct`},
+		{`Spec[0]`, nil, `expected array, slice or map kind, not struct`},
+		{`Spec.Containers.Name`, nil, `expected struct kind, not slice`},
+		{`Spec.`, nil, `expected field name`},
+	}
+	for _, tc := range tests {
+		got, err := Lookup(give, tc.expr)
+		if err := tchkstr(t, err, tc.errStr); err != nil {
+			t.Errorf("%v: %v", tc.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(tc.exp, got) {
+			t.Errorf("DeepEqual failed for %v:\n  exp: %#v\n  got: %#v", tc.expr, tc.exp, got)
+		}
+	}
+
+	t.Run("Walk", func(t *testing.T) {
+		err := Walk(give, func(el Pair) error {
+			got, err := LookupPath(give, el.Path())
+			if err != nil {
+				t.Fatalf("expected nil err for %v, got: %v", el.Path(), err)
+			}
+			if !reflect.DeepEqual(el.Val(), got) {
+				t.Errorf("DeepEqual failed for %v:\n  exp: %#v\n  got: %#v", el.Path(), el.Val(), got)
+			}
+			if got, err = Lookup(give, el.Path().String()); err != nil {
+				t.Fatalf("expected nil err for %v, got: %v", el.Path(), err)
+			}
+			if !reflect.DeepEqual(el.Val(), got) {
+				t.Errorf("DeepEqual failed for %v:\n  exp: %#v\n  got: %#v", el.Path(), el.Val(), got)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+	})
+
+	t.Run("KeyRange", func(t *testing.T) {
+		tests := []struct {
+			give   interface{}
+			expr   string
+			exp    interface{}
+			errStr string
+		}{
+			{map[uint8]string{44: "x", 255: "y"}, `[255]`, "y", ``},
+			{map[uint8]string{44: "x"}, `[300]`, nil, `key 300 out of range for uint8`},
+			{map[uint8]string{255: "x"}, `[-1]`, nil, `key -1 out of range for uint8`},
+			{map[int8]string{-128: "x"}, `[-128]`, "x", ``},
+			{map[int8]string{127: "x"}, `[-129]`, nil, `key -129 out of range for int8`},
+			{map[int64]string{-1: "neg"}, `[18446744073709551615]`, nil,
+				`key 18446744073709551615 out of range for int64`},
+			{map[uint64]string{1<<64 - 1: "max"}, `[18446744073709551615]`, "max", ``},
+			{map[uint32]string{1: "x"}, `[18446744073709551615]`, nil,
+				`key 18446744073709551615 out of range for uint32`},
+		}
+		for _, tc := range tests {
+			got, err := Lookup(tc.give, tc.expr)
+			if err := tchkstr(t, err, tc.errStr); err != nil {
+				t.Errorf("%v: %v", tc.expr, err)
+				continue
+			}
+			if !reflect.DeepEqual(tc.exp, got) {
+				t.Errorf("DeepEqual failed for %v:\n  exp: %#v\n  got: %#v", tc.expr, tc.exp, got)
+			}
+		}
+	})
+
+	t.Run("ChanSeq", func(t *testing.T) {
+		_, err := LookupPath(make(chan int), Path{ChanSeq(0)})
+		if err := tchkstr(t, err, `channel elements can not be looked up`); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package iter
+
+import (
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// defaultOpaque holds the values given to Opaque by DefaultOpaque.
+var defaultOpaque = []interface{}{
+	time.Time{}, time.Location{},
+	big.Int{}, big.Float{}, big.Rat{},
+	net.IP{}, net.IPMask{}, net.IPNet{}, net.HardwareAddr{},
+	netip.Addr{}, netip.Prefix{}, netip.AddrPort{},
+	url.URL{}, url.Userinfo{},
+	regexp.Regexp{},
+	sync.Mutex{}, sync.RWMutex{}, sync.WaitGroup{}, sync.Once{},
+	reflect.Value{},
+}
+
+// opaqueTypes holds the types registered through Opaque, it is shared by each
+// copy of a walker so the result of matching each type is cached.
+type opaqueTypes struct {
+	types  map[reflect.Type]bool
+	ifaces []reflect.Type
+	cache  sync.Map
+}
+
+func (o *opaqueTypes) add(value interface{}) {
+	typ := reflect.TypeOf(value)
+	if typ == nil {
+		return
+	}
+	if typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Interface &&
+		reflect.ValueOf(value).IsNil() {
+		o.ifaces = append(o.ifaces, typ.Elem())
+		return
+	}
+	o.types[typ] = true
+}
+
+// has reports if values of typ are opaque, because it was registered or it or
+// a pointer to it implements a registered interface.
+func (o *opaqueTypes) has(typ reflect.Type) bool {
+	if res, ok := o.cache.Load(typ); ok {
+		return res.(bool)
+	}
+	res := o.types[typ]
+	for _, iface := range o.ifaces {
+		if res {
+			break
+		}
+		res = typ.Implements(iface) || (typ.Kind() != reflect.Interface &&
+			reflect.PtrTo(typ).Implements(iface))
+	}
+	o.cache.Store(typ, res)
+	return res
+}
@@ -0,0 +1,123 @@
+package iter
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testOpaqueStringer struct {
+	A, B int
+}
+
+func (s *testOpaqueStringer) String() string {
+	return fmt.Sprintf("%d.%d", s.A, s.B)
+}
+
+func TestOpaque(t *testing.T) {
+	type testOpaque struct {
+		Time     time.Time
+		TimePtr  *time.Time
+		Int      *big.Int
+		IP       net.IP
+		Mu       sync.Mutex
+		Stringer testOpaqueStringer
+		List     *testWalkableList
+		Iface    interface{}
+		Slice    []int
+	}
+	now := time.Now()
+	give := &testOpaque{
+		Time:     now,
+		TimePtr:  &now,
+		Int:      big.NewInt(10),
+		IP:       net.IPv4(127, 0, 0, 1),
+		Stringer: testOpaqueStringer{1, 2},
+		List:     newTestWalkableList(1),
+		Iface:    now,
+		Slice:    []int{1},
+	}
+	visit := func(opts ...WalkerOption) map[string]interface{} {
+		res := make(map[string]interface{})
+		err := NewWalker(NewIter(), opts...).Walk(give, func(el Pair) error {
+			res[el.Path().String()] = el.Val()
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		return res
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		res := visit()
+		for _, path := range []string{".Time", ".IP", ".Mu", ".Stringer"} {
+			if _, ok := res[path]; ok {
+				t.Fatalf("expected %v to be walked into", path)
+			}
+		}
+	})
+
+	t.Run("DefaultOpaque", func(t *testing.T) {
+		exp := map[string]interface{}{
+			".Time":       now,
+			".TimePtr":    &now,
+			".Int":        give.Int,
+			".IP":         give.IP,
+			".Mu":         sync.Mutex{},
+			".Stringer.A": 1,
+			".Stringer.B": 2,
+			".List[0]":    1,
+			".Iface":      now,
+			".Slice[0]":   1,
+		}
+		if res := visit(DefaultOpaque()); !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("Interface", func(t *testing.T) {
+		exp := map[string]interface{}{
+			".Time":     now,
+			".TimePtr":  &now,
+			".Int":      give.Int,
+			".IP":       give.IP,
+			".Mu":       sync.Mutex{},
+			".Stringer": testOpaqueStringer{1, 2},
+			".List[0]":  1,
+			".Iface":    now,
+			".Slice[0]": 1,
+		}
+		res := visit(Opaque((*fmt.Stringer)(nil), sync.Mutex{}))
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("Walkable", func(t *testing.T) {
+		res := visit(Opaque(testWalkableList{}, []int{}))
+		if _, ok := res[".List"]; !ok {
+			t.Fatalf("expected Walkable to be opaque, got: %v", res)
+		}
+		if _, ok := res[".Slice"]; !ok {
+			t.Fatalf("expected slice to be opaque, got: %v", res)
+		}
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		o := &opaqueTypes{types: make(map[reflect.Type]bool)}
+		o.add(nil)
+		o.add((*fmt.Stringer)(nil))
+		o.add((*int)(nil))
+		if len(o.types) != 1 || len(o.ifaces) != 1 {
+			t.Fatalf("expected 1 type and 1 interface, got: %v %v", o.types, o.ifaces)
+		}
+		if !o.has(reflect.TypeOf(new(int))) || o.has(reflect.TypeOf(0)) {
+			t.Fatal("expected only *int to be opaque")
+		}
+	})
+}
@@ -62,6 +62,38 @@ type Pair interface {
 
 	// Pair returns the key and value for this Pair.
 	Pair() (interface{}, interface{})
+
+	// Value returns the reflect.Value of Val() as it was given by the Iterator,
+	// avoiding the need to reflect on it again. Unlike Val() it retains the
+	// static type of the element, so a nil interface{} struct field will have
+	// a Value of kind reflect.Interface rather than an invalid Value.
+	Value() reflect.Value
+
+	// Type returns the type of Value(), or nil if it is not valid.
+	Type() reflect.Type
+
+	// Kind returns the kind of Value(), or reflect.Invalid if it is not valid.
+	Kind() reflect.Kind
+
+	// Truncated returns true if this Pair holds a structured value whose
+	// children were not visited because the Walker reached its MaxDepth.
+	Truncated() bool
+
+	// Path returns the location of this Pair relative to the top most element
+	// by following each Parent().
+	Path() Path
+
+	// Set will replace the value this Pair was retrieved from, which requires
+	// the Walker to have been given a pointer so the element is addressable.
+	// An error is returned if the location can not be set, for example when it
+	// is an unexported struct field or the value is not assignable to it.
+	// Elements of maps are set by replacing the map entry for Key().
+	Set(value interface{}) error
+
+	// Clone returns a copy of this Pair and each of its parents, which remains
+	// valid after the walk func returns when the Walker is pooling Pairs. A
+	// CycleError held by the Pair will refer to the copy of its Ancestor.
+	Clone() Pair
 }
 
 type pair struct {
@@ -69,6 +101,48 @@ type pair struct {
 	val interface{}
 	pnt Pair
 	err error
+	ref ref
+	via reflect.Kind
+
+	// rv is the value as given by the Iterator, the map value mv is held for
+	// map elements since they can't be addressed.
+	rv reflect.Value
+	mv reflect.Value
+
+	// trunc is set by Walkers that did not expand this Pair due to MaxDepth.
+	trunc bool
+
+	// readonly holds the reason Set must fail when it is known regardless of
+	// the value, such as for Pairs visited by a parallel walk.
+	readonly string
+
+	// pooled is set for Pairs created by a Walker, which may be reused once
+	// released, rather than by NewPair.
+	pooled bool
+}
+
+// ref identifies the memory backing a pointer, map or slice value so a Walker
+// may detect cycles. The type is included since a struct and its first field
+// share an address.
+type ref struct {
+	typ reflect.Type
+	ptr uintptr
+	len int
+}
+
+func refOf(value interface{}) ref {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		if !v.IsNil() {
+			return ref{typ: v.Type(), ptr: v.Pointer()}
+		}
+	case reflect.Slice:
+		if v.Len() > 0 {
+			return ref{typ: v.Type(), ptr: v.Pointer(), len: v.Len()}
+		}
+	}
+	return ref{}
 }
 
 // NewPair returns a new key-value pair to be used by Walkers.
@@ -78,6 +152,8 @@ func NewPair(parent Pair, key, value interface{}, err error) Pair {
 		val: value,
 		pnt: parent,
 		err: err,
+		ref: refOf(value),
+		rv:  reflect.ValueOf(value),
 	}
 }
 
@@ -108,6 +184,111 @@ func (pr *pair) Pair() (key, val interface{}) {
 	return pr.key, pr.val
 }
 
+func (pr *pair) Value() reflect.Value {
+	return pr.rv
+}
+
+func (pr *pair) Type() reflect.Type {
+	if !pr.rv.IsValid() {
+		return nil
+	}
+	return pr.rv.Type()
+}
+
+func (pr *pair) Kind() reflect.Kind {
+	return pr.rv.Kind()
+}
+
+func (pr *pair) Truncated() bool {
+	return pr.trunc
+}
+
+func (pr *pair) Path() Path {
+	return pathOf(pr)
+}
+
+func (pr *pair) Set(value interface{}) error {
+	if len(pr.readonly) > 0 {
+		return fmt.Errorf("cannot set %v: %s", pr.Path(), pr.readonly)
+	}
+	if pr.mv.IsValid() {
+		nv, err := assignValue(value, pr.mv.Type().Elem())
+		if err != nil {
+			return err
+		}
+		if !pr.mv.CanInterface() {
+			return fmt.Errorf("cannot set %v: map is obtained through unexported "+
+				"field", pr.Path())
+		}
+		// The key is taken from the interface value since an Iterator may
+		// reuse the reflect.Value it was given in.
+		kv := reflect.ValueOf(pr.key)
+		if !kv.IsValid() {
+			kv = reflect.Zero(pr.mv.Type().Key())
+		}
+		pr.mv.SetMapIndex(kv, nv)
+		pr.rv, pr.val = nv, nv.Interface()
+		return nil
+	}
+
+	switch {
+	case !pr.rv.IsValid():
+		return fmt.Errorf("cannot set %v: pair holds no value", pr.Path())
+	case !pr.rv.CanAddr():
+		return fmt.Errorf("cannot set %v: value is not addressable, walk a "+
+			"pointer to modify values", pr.Path())
+	case !pr.rv.CanSet():
+		return fmt.Errorf("cannot set %v: value is obtained through unexported "+
+			"field", pr.Path())
+	}
+	nv, err := assignValue(value, pr.rv.Type())
+	if err != nil {
+		return err
+	}
+	pr.rv.Set(nv)
+	pr.val = pr.rv.Interface()
+	return nil
+}
+
+func (pr *pair) Clone() Pair {
+	cpy := *pr
+	cpy.pooled = false
+	if pr.pnt == nil {
+		return &cpy
+	}
+	cpy.pnt = pr.pnt.Clone()
+
+	if ce, ok := pr.err.(*CycleError); ok {
+		from, to := pr.pnt, cpy.pnt
+		for ; from != nil; from, to = from.Parent(), to.Parent() {
+			if from == ce.Ancestor {
+				cpy.err = &CycleError{Ancestor: to}
+				break
+			}
+		}
+	}
+	return &cpy
+}
+
+// assignValue returns value as a reflect.Value that may be assigned to typ,
+// a nil value is converted to the zero value of any nillable type.
+func assignValue(value interface{}, typ reflect.Type) (reflect.Value, error) {
+	nv := reflect.ValueOf(value)
+	if !nv.IsValid() {
+		switch typ.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+			reflect.Ptr, reflect.Slice:
+			return reflect.Zero(typ), nil
+		}
+		return nv, fmt.Errorf("cannot set nil as type %s", typ)
+	}
+	if !nv.Type().AssignableTo(typ) {
+		return nv, fmt.Errorf("cannot set value of type %s as type %s",
+			nv.Type(), typ)
+	}
+	return nv, nil
+}
+
 func (pr *pair) String() string {
 	var k string
 	if v, ok := pr.key.(reflect.StructField); ok {
@@ -98,3 +98,217 @@ func TestPair(t *testing.T) {
 		}
 	})
 }
+
+func TestPairSet(t *testing.T) {
+	type testPairSetInner struct {
+		Secret string
+	}
+	type testPairSet struct {
+		Name     string
+		Secret   string
+		Inner    testPairSetInner
+		InnerPtr *testPairSetInner
+		Slice    []string
+		Array    [2]string
+		Map      map[string]string
+		Iface    interface{}
+		private  string
+	}
+	newGive := func() *testPairSet {
+		return &testPairSet{
+			Name:     "name",
+			Secret:   "secret",
+			Inner:    testPairSetInner{"secret"},
+			InnerPtr: &testPairSetInner{"secret"},
+			Slice:    []string{"secret", "name"},
+			Array:    [2]string{"name", "secret"},
+			Map:      map[string]string{"a": "secret", "b": "name"},
+			Iface:    "secret",
+			private:  "secret",
+		}
+	}
+	redact := func(el Pair) error {
+		if el.Val() == "secret" {
+			return el.Set("xxx")
+		}
+		return nil
+	}
+
+	t.Run("Redact", func(t *testing.T) {
+		for _, w := range []Walker{NewWalker(NewIter()), NewBFSWalker(NewIter())} {
+			give := newGive()
+			if err := w.Walk(give, redact); err != nil {
+				t.Fatalf("expected nil err, got: %v", err)
+			}
+			exp := newGive()
+			exp.Secret, exp.Inner.Secret, exp.InnerPtr.Secret = "xxx", "xxx", "xxx"
+			exp.Slice[0], exp.Array[1], exp.Map["a"], exp.Iface = "xxx", "xxx", "xxx", "xxx"
+			if !reflect.DeepEqual(exp, give) {
+				t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, give)
+			}
+		}
+	})
+
+	t.Run("Pair", func(t *testing.T) {
+		give := newGive()
+		err := Walk(give, func(el Pair) error {
+			if el.Val() != "secret" {
+				return nil
+			}
+			if err := el.Set("xxx"); err != nil {
+				return err
+			}
+			if got := el.Val(); got != "xxx" {
+				t.Errorf("expected Val() of xxx after Set, got: %v", got)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+	})
+
+	t.Run("Containers", func(t *testing.T) {
+		give := newGive()
+		w := NewWalker(NewIter(), VisitContainers())
+		var names []string
+		err := w.Walk(give, func(el Pair) error {
+			if sf, ok := el.Key().(reflect.StructField); ok && sf.Name == "Slice" {
+				return el.Set([]string{"a", "b", "c"})
+			}
+			if el.Parent() != nil && el.Parent().Key() != nil {
+				if sf := el.Parent().Key().(reflect.StructField); sf.Name == "Slice" {
+					names = append(names, el.Val().(string))
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if exp := []string{"a", "b", "c"}; !reflect.DeepEqual(exp, names) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, names)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		give := newGive()
+		tests := []struct {
+			pr     Pair
+			val    interface{}
+			errStr string
+		}{
+			{NewPair(nil, nil, "val", nil), "xxx", `value is not addressable`},
+			{&pair{}, "xxx", `pair holds no value`},
+			{&pair{rv: reflect.ValueOf(give).Elem().FieldByName("private")},
+				"xxx", `value is obtained through unexported field`},
+			{&pair{rv: reflect.ValueOf(give).Elem().FieldByName("Name")},
+				1, `cannot set value of type int as type string`},
+			{&pair{rv: reflect.ValueOf(give).Elem().FieldByName("Name")},
+				nil, `cannot set nil as type string`},
+			{&pair{rv: reflect.ValueOf(give).Elem().FieldByName("InnerPtr")},
+				nil, ``},
+			{&pair{rv: reflect.ValueOf(give).Elem().FieldByName("Iface")},
+				1, ``},
+		}
+		for _, tc := range tests {
+			if err := tchkstr(t, tc.pr.Set(tc.val), tc.errStr); err != nil {
+				t.Error(err)
+			}
+		}
+		if give.InnerPtr != nil || give.Iface != 1 {
+			t.Errorf("expected Set to modify values, got: %#v", give)
+		}
+
+		err := Walk(*give, func(el Pair) error {
+			return el.Set("xxx")
+		})
+		if err := tchkstr(t, err, `cannot set .Name: value is not addressable`); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestPairValue(t *testing.T) {
+	type testPairValue struct {
+		Iface  interface{}
+		Err    error
+		Str    string
+		Ptr    *int
+		Nested struct{ A int }
+	}
+	give := testPairValue{Iface: nil, Str: "str"}
+
+	t.Run("Walk", func(t *testing.T) {
+		exp := map[string]reflect.Type{
+			"Iface":  reflect.TypeOf((*interface{})(nil)).Elem(),
+			"Err":    reflect.TypeOf((*error)(nil)).Elem(),
+			"Str":    reflect.TypeOf(""),
+			"Ptr":    reflect.TypeOf((*int)(nil)),
+			"A":      reflect.TypeOf(0),
+			"Nested": reflect.TypeOf(give.Nested),
+		}
+		w := NewWalker(NewIter(), VisitContainers())
+		err := w.Walk(give, func(el Pair) error {
+			if el.Parent() == nil {
+				return nil
+			}
+			name := el.Key().(reflect.StructField).Name
+			if got := el.Type(); got != exp[name] {
+				t.Errorf("Type() failed for %v:\n  exp: %v\n  got: %v", name, exp[name], got)
+			}
+			if got := el.Kind(); got != exp[name].Kind() {
+				t.Errorf("Kind() failed for %v:\n  exp: %v\n  got: %v", name, exp[name].Kind(), got)
+			}
+			if !el.Value().IsValid() || !reflect.DeepEqual(el.Value().Interface(), el.Val()) {
+				t.Errorf("Value() failed for %v:\n  exp: %#v\n  got: %#v", name, el.Val(), el.Value())
+			}
+			delete(exp, name)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if len(exp) > 0 {
+			t.Fatalf("expected all fields to be visited, missing: %v", exp)
+		}
+	})
+
+	t.Run("NewPair", func(t *testing.T) {
+		pr := NewPair(nil, "key", 1.5, nil)
+		if pr.Kind() != reflect.Float64 || pr.Type() != reflect.TypeOf(1.5) {
+			t.Errorf("expected float64 kind and type, got: %v %v", pr.Kind(), pr.Type())
+		}
+		if got := pr.Value().Float(); got != 1.5 {
+			t.Errorf("expected Value() of 1.5, got: %v", got)
+		}
+
+		pr = NewPair(nil, "key", nil, nil)
+		if pr.Kind() != reflect.Invalid || pr.Type() != nil || pr.Value().IsValid() {
+			t.Errorf("expected invalid value, got: %v %v %v", pr.Kind(), pr.Type(), pr.Value())
+		}
+	})
+}
+
+func TestPairClone(t *testing.T) {
+	root := NewPair(nil, nil, []int{1}, nil)
+	child := NewPair(root, 0, 1, nil)
+	leaf := NewPair(child, "k", "v", errors.New("exp err"))
+
+	cpy := leaf.Clone()
+	from, to := leaf, cpy
+	for ; from != nil && to != nil; from, to = from.Parent(), to.Parent() {
+		if from == to {
+			t.Fatalf("expected clone of %v to be a new pair", from)
+		}
+		if !reflect.DeepEqual(from, to) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", from, to)
+		}
+	}
+	if from != nil || to != nil {
+		t.Fatalf("expected clone to have the same depth, got: %v", cpy.Depth())
+	}
+	if exp, got := leaf.Path().String(), cpy.Path().String(); exp != got {
+		t.Fatalf("expected clone path %v, got: %v", exp, got)
+	}
+}
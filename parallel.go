@@ -0,0 +1,178 @@
+package iter
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// NewParallelWalker returns a new Walker backed by the given Iterator which
+// walks the structured elements of slices, arrays and maps on separate Go
+// routines, using at most the given number of workers at a time. When every
+// worker is busy the element is walked by the Go routine that found it, so the
+// walk never waits for a worker to become available. If workers is less than
+// one runtime.GOMAXPROCS(0) is used.
+//
+// The walk func is never called concurrently, but Pairs are visited in no
+// particular order. Once it returns an error, or the Iterator fails, the walk
+// func is not called again and the first error is returned after every worker
+// has stopped. A SkipSiblings error only prevents siblings that have not yet
+// been started from being walked. The PoolPairs and Iterative options have no
+// effect on the returned Walker.
+//
+// Values may not be modified during the walk, Set returns an error for each
+// Pair since other Go routines may be reading the value that holds it. Setting
+// a map element would otherwise write the map while its parent iterates it.
+func NewParallelWalker(iterator Iterator, workers int,
+	opts ...WalkerOption) Walker {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	w := newWalker(iterator, opts)
+	w.pool, w.iterative = false, false
+	return &parallelWalker{walker: w, workers: workers}
+}
+
+type parallelWalker struct {
+	walker
+	workers int
+}
+
+func (w parallelWalker) Walk(value interface{}, f func(el Pair) error) error {
+	return w.WalkContext(context.Background(), value, f)
+}
+
+func (w parallelWalker) WalkContext(ctx context.Context, value interface{},
+	f func(el Pair) error) error {
+	root := newRootPair(value)
+	w.ctx = ctx
+	if err := w.canceled(root); err != nil {
+		return err
+	}
+
+	// Workers are stopped through the context once any of them fails, the
+	// errors they return as a result are discarded in favor of the first.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w.ctx = ctx
+	pw := &parallelWalk{
+		w:      &w.walker,
+		f:      f,
+		sem:    make(chan struct{}, w.workers),
+		cancel: cancel,
+	}
+	if err := pw.walk(root); err != nil && err != SkipSiblings {
+		pw.fail(err)
+	}
+	pw.wg.Wait()
+	return pw.err
+}
+
+// parallelWalk holds the state of a single walk shared by each worker.
+type parallelWalk struct {
+	w      *walker
+	wg     sync.WaitGroup
+	sem    chan struct{}
+	cancel context.CancelFunc
+
+	// mu serializes calls to f and guards err.
+	mu  sync.Mutex
+	f   func(el Pair) error
+	err error
+}
+
+func (pw *parallelWalk) walk(el Pair) error {
+	if pr, ok := el.(*pair); ok {
+		pr.readonly = "values can not be set during a parallel walk"
+	}
+
+	in, ok := pw.w.structured(el)
+	if !ok {
+		if err := pw.visit(el); err != SkipChildren {
+			return err
+		}
+		return nil
+	}
+	if pw.w.containers {
+		switch err := pw.visit(el); err {
+		case nil:
+		case SkipChildren:
+			return nil
+		default:
+			return err
+		}
+	}
+
+	var dispatch bool
+	switch in.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		dispatch = true
+	}
+
+	var stop int32
+	err := pw.w.expand(el, in, func(child Pair) error {
+		if atomic.LoadInt32(&stop) != 0 {
+			return SkipSiblings
+		}
+		if _, ok := pw.w.structured(child); !dispatch || !ok {
+			return pw.walkInline(child)
+		}
+		select {
+		case pw.sem <- struct{}{}:
+		default:
+			return pw.walkInline(child)
+		}
+
+		pw.wg.Add(1)
+		go func() {
+			defer func() {
+				<-pw.sem
+				pw.wg.Done()
+			}()
+			switch err := pw.walk(child); {
+			case err == SkipSiblings:
+				atomic.StoreInt32(&stop, 1)
+			case err != nil:
+				pw.fail(err)
+			}
+		}()
+		return nil
+	})
+	if err == SkipSiblings {
+		return nil
+	}
+	return err
+}
+
+// walkInline walks child on the current Go routine, recording any error that
+// will end the walk as soon as it occurs so the workers may be stopped.
+func (pw *parallelWalk) walkInline(child Pair) error {
+	err := pw.walk(child)
+	if err != nil && err != SkipSiblings {
+		pw.fail(err)
+	}
+	return err
+}
+
+// visit calls f with el unless the walk has already failed, in which case the
+// first error is returned.
+func (pw *parallelWalk) visit(el Pair) error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if pw.err != nil {
+		return pw.err
+	}
+	return pw.f(el)
+}
+
+// fail records err if it is the first error of the walk and stops the workers.
+func (pw *parallelWalk) fail(err error) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if pw.err == nil {
+		pw.err = err
+		pw.cancel()
+	}
+}
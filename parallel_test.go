@@ -0,0 +1,155 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallelWalker(t *testing.T) {
+	type testParallelNode struct {
+		Name     string
+		Children []*testParallelNode
+		Attrs    map[string][]int
+	}
+	var tree func(depth int, name string) *testParallelNode
+	tree = func(depth int, name string) *testParallelNode {
+		n := &testParallelNode{Name: name, Attrs: map[string][]int{
+			"a": {depth, depth + 1}, "b": {depth * 2}}}
+		if depth > 0 {
+			for i := 0; i < 4; i++ {
+				child := tree(depth-1, fmt.Sprintf("%v.%d", name, i))
+				n.Children = append(n.Children, child)
+			}
+		}
+		return n
+	}
+	give := []interface{}{tree(4, "x"), tree(3, "y"), []int{1, 2, 3}}
+
+	paths := func(w Walker) map[string]interface{} {
+		res := make(map[string]interface{})
+		var active int32
+		err := w.Walk(give, func(el Pair) error {
+			if atomic.AddInt32(&active, 1) != 1 {
+				t.Error("expected walk func to not be called concurrently")
+			}
+			defer atomic.AddInt32(&active, -1)
+			res[el.Path().String()] = el.Val()
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		return res
+	}
+
+	t.Run("Walk", func(t *testing.T) {
+		exp := paths(NewWalker(NewIter()))
+		for _, workers := range []int{0, 1, 2, 8, 64} {
+			res := paths(NewParallelWalker(NewIter(), workers))
+			if !reflect.DeepEqual(exp, res) {
+				t.Fatalf("DeepEqual failed for %v workers:\n  exp: %#v\n  got: %#v",
+					workers, exp, res)
+			}
+		}
+	})
+
+	t.Run("ReuseMapValues", func(t *testing.T) {
+		exp := paths(NewWalker(NewIter()))
+		res := paths(NewParallelWalker(&Iter{ReuseMapValues: true}, 8))
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("VisitContainers", func(t *testing.T) {
+		exp := paths(NewWalker(NewIter(), VisitContainers()))
+		res := paths(NewParallelWalker(NewIter(), 8, VisitContainers()))
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		m := map[string][]int{"a": {1}, "b": {2}, "c": {3}}
+		var n int
+		w := NewParallelWalker(NewIter(), 8, VisitContainers())
+		err := w.Walk(&m, func(el Pair) error {
+			n++
+			err := el.Set(el.Val())
+			return tchkstr(t, err, `values can not be set during a parallel walk`)
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if n != 7 {
+			t.Fatalf("expected 7 visits, got: %v", n)
+		}
+	})
+
+	t.Run("SkipChildren", func(t *testing.T) {
+		var visits int
+		w := NewParallelWalker(NewIter(), 8, VisitContainers())
+		err := w.Walk(give, func(el Pair) error {
+			visits++
+			if el.Depth() == 1 {
+				return SkipChildren
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if exp := 1 + len(give); visits != exp {
+			t.Fatalf("expected %v visits, got: %v", exp, visits)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		errStop := errors.New("stop")
+		var failed bool
+		w := NewParallelWalker(NewIter(), 8)
+		err := w.Walk(give, func(el Pair) error {
+			if failed {
+				t.Fatal("expected no visits after an error")
+			}
+			if el.Val() == 2 {
+				failed = true
+				return errStop
+			}
+			return nil
+		})
+		if err != errStop {
+			t.Fatalf("expected err %v, got: %v", errStop, err)
+		}
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var visits int
+		w := NewParallelWalker(NewIter(), 8).(ContextWalker)
+		err := w.WalkContext(ctx, give, func(el Pair) error {
+			if visits++; visits == 10 {
+				cancel()
+			}
+			return nil
+		})
+		var pe *PathError
+		if !errors.As(err, &pe) || pe.Err != context.Canceled {
+			t.Fatalf("expected PathError holding context.Canceled, got: %v", err)
+		}
+
+		err = w.WalkContext(ctx, give, func(el Pair) error {
+			t.Fatal("expected no visits once canceled")
+			return nil
+		})
+		if !errors.As(err, &pe) || pe.Err != context.Canceled {
+			t.Fatalf("expected PathError holding context.Canceled, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package iter
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// A Path describes the location of a Pair from the root value of a walk, with
+// one Step for each structured value it is nested within.
+type Path []Step
+
+// String returns the path in Go syntax relative to the root value, such as
+// `.Packages[2].Meta["owner"]`. The root Pair has an empty path.
+func (p Path) String() string {
+	var buf bytes.Buffer
+	for _, s := range p {
+		buf.WriteString(s.String())
+	}
+	return buf.String()
+}
+
+// A Step is a single element of a Path, it will be one of Field, Index,
+// MapKey or ChanSeq.
+type Step interface {
+	String() string
+	isStep()
+}
+
+// Field is a Step into the field of a struct.
+type Field reflect.StructField
+
+func (s Field) isStep() {}
+
+// String returns the field name prefixed with a period.
+func (s Field) String() string {
+	return "." + s.Name
+}
+
+// Index is a Step into the element of an array or slice.
+type Index int
+
+func (s Index) isStep() {}
+
+// String returns the index enclosed in brackets.
+func (s Index) String() string {
+	return fmt.Sprintf("[%d]", int(s))
+}
+
+// MapKey is a Step into the element of a map, holding the key.
+type MapKey struct {
+	Key interface{}
+}
+
+func (s MapKey) isStep() {}
+
+// String returns the key enclosed in brackets, string keys are quoted.
+func (s MapKey) String() string {
+	if str, ok := s.Key.(string); ok {
+		return fmt.Sprintf("[%q]", str)
+	}
+	return fmt.Sprintf("[%v]", s.Key)
+}
+
+// ChanSeq is a Step into a value received from a channel, holding the
+// sequence number of the receive as described in Pair.
+type ChanSeq int
+
+func (s ChanSeq) isStep() {}
+
+// String returns the sequence number enclosed in brackets and prefixed by the
+// receive operator.
+func (s ChanSeq) String() string {
+	return fmt.Sprintf("[<-%d]", int(s))
+}
+
+// pathOf returns the Path from the root Pair to el.
+func pathOf(el Pair) Path {
+	var p Path
+	for ; el != nil && el.Parent() != nil; el = el.Parent() {
+		p = append(p, stepOf(el))
+	}
+	for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+		p[i], p[j] = p[j], p[i]
+	}
+	return p
+}
+
+// stepOf returns the Step from the parent of el to el. The kind of the parent
+// value is recorded by Walkers, but for Pairs created elsewhere it must be
+// derived from the parent value.
+func stepOf(el Pair) Step {
+	key := el.Key()
+	if sf, ok := key.(reflect.StructField); ok {
+		return Field(sf)
+	}
+
+	via := reflect.Invalid
+	if pr, ok := el.(*pair); ok {
+		via = pr.via
+	}
+	if via == reflect.Invalid {
+		via = indirectValue(el.Parent().Value()).Kind()
+	}
+
+	if seq, ok := key.(int); ok {
+		switch via {
+		case reflect.Slice, reflect.Array:
+			return Index(seq)
+		case reflect.Chan:
+			return ChanSeq(seq)
+		}
+	}
+	return MapKey{key}
+}
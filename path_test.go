@@ -0,0 +1,118 @@
+package iter
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestStepInterface(t *testing.T) {
+	var _ Step = Field{}
+	var _ Step = Index(0)
+	var _ Step = MapKey{}
+	var _ Step = ChanSeq(0)
+}
+
+func TestPath(t *testing.T) {
+	type testPathMeta struct {
+		Meta map[string]string
+		IDs  map[int]string
+	}
+	type testPath struct {
+		Name     string
+		Packages []testPathMeta
+		Chan     chan string
+	}
+	give := &testPath{
+		Name: "root",
+		Packages: []testPathMeta{{
+			Meta: map[string]string{"owner": "a"},
+			IDs:  map[int]string{3: "b"}},
+		},
+		Chan: make(chan string, 2),
+	}
+	give.Chan <- "c"
+	give.Chan <- "d"
+
+	t.Run("String", func(t *testing.T) {
+		sf, _ := reflect.TypeOf(testPath{}).FieldByName("Packages")
+		tests := []struct {
+			give Path
+			exp  string
+		}{
+			{nil, ``},
+			{Path{Field(sf)}, `.Packages`},
+			{Path{Field(sf), Index(2)}, `.Packages[2]`},
+			{Path{Field(sf), Index(2), MapKey{"owner"}}, `.Packages[2]["owner"]`},
+			{Path{MapKey{1.5}, MapKey{"a\"b"}}, `[1.5]["a\"b"]`},
+			{Path{ChanSeq(1)}, `[<-1]`},
+		}
+		for _, tc := range tests {
+			if got := tc.give.String(); got != tc.exp {
+				t.Errorf("String() failed:\n  exp: %v\n  got: %v", tc.exp, got)
+			}
+		}
+	})
+
+	t.Run("Walk", func(t *testing.T) {
+		res := make(map[string]interface{})
+		w := NewWalker(&Iter{ChanRecv: true})
+		err := w.Walk(give, func(el Pair) error {
+			res[el.Path().String()] = el.Val()
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		exp := map[string]interface{}{
+			`.Name`:                      "root",
+			`.Packages[0].Meta["owner"]`: "a",
+			`.Packages[0].IDs[3]`:        "b",
+			`.Chan[<-0]`:                 "c",
+			`.Chan[<-1]`:                 "d",
+		}
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("Steps", func(t *testing.T) {
+		var got Path
+		err := Walk(give, func(el Pair) error {
+			if el.Val() == "b" {
+				got = el.Path()
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if len(got) != 4 {
+			t.Fatalf("expected 4 steps, got: %v", got)
+		}
+		if sf, ok := got[0].(Field); !ok || sf.Name != "Packages" {
+			t.Errorf("expected Field step, got: %#v", got[0])
+		}
+		if got[1] != Index(0) {
+			t.Errorf("expected Index step, got: %#v", got[1])
+		}
+		if got[3] != (MapKey{3}) {
+			t.Errorf("expected MapKey step, got: %#v", got[3])
+		}
+	})
+
+	t.Run("NewPair", func(t *testing.T) {
+		root := NewPair(nil, nil, []map[int]int{{1: 2}}, nil)
+		sl := NewPair(root, 0, map[int]int{1: 2}, nil)
+		m := NewPair(sl, 1, 2, nil)
+		if exp, got := `[0][1]`, fmt.Sprintf("%v", m.Path()); exp != got {
+			t.Errorf("Path() failed:\n  exp: %v\n  got: %v", exp, got)
+		}
+		if _, ok := m.Path()[1].(MapKey); !ok {
+			t.Errorf("expected MapKey step, got: %#v", m.Path()[1])
+		}
+		if got := root.Path(); len(got) != 0 {
+			t.Errorf("expected empty root path, got: %v", got)
+		}
+	})
+}
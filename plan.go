@@ -0,0 +1,134 @@
+package iter
+
+import (
+	"reflect"
+	"sync"
+)
+
+// plans caches a *typePlan for each reflect.Type that has been iterated or
+// walked, it is never cleared since the number of types in a program is fixed.
+var plans sync.Map
+
+// typePlan holds the facts about a type that Iterators and Walkers would
+// otherwise derive through reflection for each value they visit.
+type typePlan struct {
+	kind reflect.Kind
+
+	// leaf is true when values of the type can never hold a structured value,
+	// not even after following pointers.
+	leaf bool
+
+	// walkable is set when the type implements Walkable and walkablePtr when
+	// only a pointer to it does.
+	walkable, walkablePtr bool
+
+	// fields holds each field of a struct type. Each indexes entry holds the
+	// positions of fields which remain after filtering by planFilter.
+	fields  []fieldPlan
+	indexes [4][]int
+}
+
+type fieldPlan struct {
+	field reflect.StructField
+
+	// key holds field as an interface value so each Pair visited for it
+	// shares a single allocation.
+	key interface{}
+}
+
+// planFilter returns the indexes entry of a typePlan for the given exclusions.
+func planFilter(excludeAnonymous, excludeUnexported bool) int {
+	var i int
+	if excludeAnonymous {
+		i |= 1
+	}
+	if excludeUnexported {
+		i |= 2
+	}
+	return i
+}
+
+// planOf returns the plan for typ, compiling it on first use.
+func planOf(typ reflect.Type) *typePlan {
+	if tp, ok := plans.Load(typ); ok {
+		return tp.(*typePlan)
+	}
+	tp, _ := plans.LoadOrStore(typ, compilePlan(typ))
+	return tp.(*typePlan)
+}
+
+func compilePlan(typ reflect.Type) *typePlan {
+	tp := &typePlan{kind: typ.Kind(), leaf: isLeafType(typ)}
+	tp.walkable = typ.Implements(walkableType)
+	if !tp.walkable && typ.Kind() != reflect.Interface {
+		tp.walkablePtr = reflect.PtrTo(typ).Implements(walkableType)
+	}
+	if tp.kind != reflect.Struct {
+		return tp
+	}
+
+	tp.fields = make([]fieldPlan, typ.NumField())
+	for i := range tp.fields {
+		field := typ.Field(i)
+		tp.fields[i] = fieldPlan{field: field, key: field}
+		for j := range tp.indexes {
+			if field.Anonymous && j&1 != 0 {
+				continue
+			}
+			if len(field.PkgPath) > 0 && j&2 != 0 {
+				continue
+			}
+			tp.indexes[j] = append(tp.indexes[j], i)
+		}
+	}
+	return tp
+}
+
+// isLeafType reports if indirectValue could never return a structured value
+// for values of typ. Interfaces are never leaves since their dynamic type is
+// unknown, pointers are leaves when the type they point to is. Types that
+// implement Walkable are never leaves.
+func isLeafType(typ reflect.Type) bool {
+	var seen map[reflect.Type]bool
+	for {
+		if typ.Implements(walkableType) ||
+			reflect.PtrTo(typ).Implements(walkableType) {
+			return false
+		}
+		switch typ.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Struct, reflect.Chan,
+			reflect.Map, reflect.Interface:
+			return false
+		case reflect.Ptr:
+			if seen[typ] {
+				// Circular types such as `type Element *Element`.
+				return true
+			}
+			if seen == nil {
+				seen = make(map[reflect.Type]bool)
+			}
+			seen[typ] = true
+			typ = typ.Elem()
+		default:
+			return true
+		}
+	}
+}
+
+// fieldKey returns field as an interface value, reusing the one held by the
+// plan of the struct type when the Iterator gave the field unmodified.
+func (tp *typePlan) fieldKey(field reflect.StructField) interface{} {
+	if len(field.Index) != 1 || field.Index[0] >= len(tp.fields) {
+		return field
+	}
+	fp := &tp.fields[field.Index[0]]
+	if !sameField(fp.field, field) {
+		return field
+	}
+	return fp.key
+}
+
+func sameField(a, b reflect.StructField) bool {
+	return a.Name == b.Name && a.PkgPath == b.PkgPath && a.Type == b.Type &&
+		a.Tag == b.Tag && a.Offset == b.Offset && a.Anonymous == b.Anonymous
+}
@@ -0,0 +1,110 @@
+package iter
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestPlanOf(t *testing.T) {
+	type testPlanCircular *testPlanCircular
+	type testPlanEmbedded struct{ A int }
+	type testPlanStruct struct {
+		testPlanEmbedded
+		Exported   string
+		unexported string
+		*TestTree
+	}
+
+	t.Run("Leaf", func(t *testing.T) {
+		type testPlanLeaf struct {
+			val interface{}
+			exp bool
+		}
+		tests := []testPlanLeaf{
+			{0, true},
+			{"str", true},
+			{new(int), true},
+			{new(*string), true},
+			{testPlanCircular(nil), true},
+			{func() {}, true},
+			{[]int{}, false},
+			{[1]int{}, false},
+			{map[int]int{}, false},
+			{make(chan int), false},
+			{testPlanStruct{}, false},
+			{new(testPlanStruct), false},
+			{new(interface{}), false},
+			{new([]*int), false},
+		}
+		for _, test := range tests {
+			typ := reflect.TypeOf(test.val)
+			tp := planOf(typ)
+			if tp.kind != typ.Kind() {
+				t.Errorf("expected kind %v for %v, got: %v", typ.Kind(), typ, tp.kind)
+			}
+			if tp.leaf != test.exp {
+				t.Errorf("expected leaf %v for %v, got: %v", test.exp, typ, tp.leaf)
+			}
+			if got := planOf(typ); got != tp {
+				t.Errorf("expected plan for %v to be cached", typ)
+			}
+		}
+	})
+
+	t.Run("Fields", func(t *testing.T) {
+		tp := planOf(reflect.TypeOf(testPlanStruct{}))
+		exp := [4][]int{{0, 1, 2, 3}, {1, 2}, {1, 3}, {1}}
+		if !reflect.DeepEqual(exp, tp.indexes) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, tp.indexes)
+		}
+		for i, fp := range tp.fields {
+			if fp.field.Index[0] != i {
+				t.Fatalf("expected field %v at index %v", fp.field.Name, i)
+			}
+			if !reflect.DeepEqual(fp.key, tp.fieldKey(fp.field)) {
+				t.Fatalf("expected key of field %v from fieldKey", fp.field.Name)
+			}
+			n := testing.AllocsPerRun(10, func() {
+				fp.key = tp.fieldKey(fp.field)
+			})
+			if n != 0 {
+				t.Fatalf("expected key of field %v to be shared, got: %v allocs",
+					fp.field.Name, n)
+			}
+		}
+
+		field := tp.fields[1].field
+		field.Tag = `json:"exported"`
+		key := tp.fieldKey(field)
+		if sf := key.(reflect.StructField); sf.Tag != field.Tag {
+			t.Fatalf("expected modified field from fieldKey, got: %v", sf)
+		}
+		field.Index = []int{len(tp.fields)}
+		sf := tp.fieldKey(field).(reflect.StructField)
+		if sf.Index[0] != len(tp.fields) {
+			t.Fatalf("expected out of range field from fieldKey, got: %v", sf)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		type testPlanConcurrent struct{ A, B, C int }
+		typ := reflect.TypeOf(testPlanConcurrent{})
+
+		var wg sync.WaitGroup
+		res := make([]*typePlan, 8)
+		for i := range res {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				res[i] = planOf(typ)
+			}(i)
+		}
+		wg.Wait()
+		for _, tp := range res {
+			if tp != res[0] {
+				t.Fatal("expected each goroutine to receive the same plan")
+			}
+		}
+	})
+}
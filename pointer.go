@@ -0,0 +1,262 @@
+package iter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// JSONPointer returns the path as a RFC 6901 JSON Pointer such as
+// `/packages/2/meta/owner`. Struct fields are named as encoding/json names them,
+// by their json tag when present, and embedded structs without a tag are left
+// out since their fields are promoted into the enclosing object. Map keys are
+// formatted with the %v verb. It reports false when the path passes through a
+// field encoding/json never marshals, such as one tagged `json:"-"`, as there
+// is no such location within the JSON document.
+func (p Path) JSONPointer() (string, bool) {
+	var buf bytes.Buffer
+	for _, s := range p {
+		tok, ok := pointerToken(s)
+		if !ok {
+			return "", false
+		}
+		if _, ok := s.(Field); ok && len(tok) == 0 {
+			continue
+		}
+		buf.WriteByte('/')
+		buf.WriteString(pointerEscaper.Replace(tok))
+	}
+	return buf.String(), true
+}
+
+// ResolvePointer returns the value located at the given RFC 6901 JSON Pointer
+// within value, following pointers and interfaces as Walk does. Struct fields
+// are matched by the same names JSONPointer would render them with, including
+// those promoted from embedded structs, so the two may be used to go in either
+// direction.
+func ResolvePointer(value interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	r := resolver{Iterator: &defaultIter}
+	cur := value
+	for _, tok := range tokens {
+		in := reflect.ValueOf(indirect(cur))
+
+		var next reflect.Value
+		switch in.Kind() {
+		case reflect.Struct:
+			next, err = r.jsonField(in, tok)
+		case reflect.Slice, reflect.Array:
+			var idx int
+			if idx, err = pointerIndex(tok); err == nil {
+				next, err = r.index(in, idx)
+			}
+		case reflect.Map:
+			next, err = r.key(in, func(key reflect.Value) bool {
+				return fmt.Sprintf("%v", key.Interface()) == tok
+			})
+		default:
+			err = fmt.Errorf("expected structured kind, not %s", in.Kind())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("json pointer %q: token %q: %v", pointer, tok, err)
+		}
+		cur = next.Interface()
+	}
+	return cur, nil
+}
+
+func parsePointer(pointer string) ([]string, error) {
+	if len(pointer) == 0 {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("json pointer %q: must begin with a '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, tok := range tokens {
+		tokens[i] = pointerUnescaper.Replace(tok)
+	}
+	return tokens, nil
+}
+
+// pointerIndex parses an array index token, which RFC 6901 requires to be
+// decimal digits without leading zeros.
+func pointerIndex(tok string) (int, error) {
+	if tok == "-" {
+		return 0, errors.New("index - refers to a nonexistent element")
+	}
+	if len(tok) == 0 || (len(tok) > 1 && tok[0] == '0') ||
+		strings.TrimLeft(tok, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	return strconv.Atoi(tok)
+}
+
+func pointerToken(s Step) (string, bool) {
+	switch T := s.(type) {
+	case Field:
+		return jsonName(reflect.StructField(T))
+	case Index:
+		return strconv.Itoa(int(T)), true
+	case ChanSeq:
+		return strconv.Itoa(int(T)), true
+	case MapKey:
+		return fmt.Sprintf("%v", T.Key), true
+	default:
+		return s.String(), true
+	}
+}
+
+// jsonName returns the name encoding/json would use for the given field, or an
+// empty name for embedded structs whose fields are promoted into the enclosing
+// object. It reports false for fields which are never marshaled.
+func jsonName(sf reflect.StructField) (string, bool) {
+	typ := sf.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if len(sf.PkgPath) > 0 && (!sf.Anonymous || typ.Kind() != reflect.Struct) {
+		return "", false
+	}
+
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if idx := strings.Index(tag, ","); idx >= 0 {
+		tag = tag[:idx]
+	}
+	if len(tag) > 0 {
+		return tag, true
+	}
+	if sf.Anonymous && typ.Kind() == reflect.Struct {
+		return "", true
+	}
+	return sf.Name, true
+}
+
+// jsonTagged reports if the json tag of sf names the field.
+func jsonTagged(sf reflect.StructField) bool {
+	tag := sf.Tag.Get("json")
+	return len(tag) > 0 && tag[0] != ','
+}
+
+// errResolved is used to halt an Iterator once the element being resolved
+// has been found.
+var errResolved = errors.New("resolved")
+
+// resolver follows a single step from a structured value to one of its
+// elements using an Iterator, it never visits the elements children.
+type resolver struct {
+	Iterator
+}
+
+func (r resolver) field(in reflect.Value,
+	match func(sf reflect.StructField) bool) (reflect.Value, error) {
+	var res reflect.Value
+	err := r.IterStruct(in, func(sf reflect.StructField, v reflect.Value) error {
+		if !v.CanInterface() || !match(sf) {
+			return nil
+		}
+		res = v
+		return errResolved
+	})
+	return r.result(res, err, "no such field")
+}
+
+// jsonField returns the field of the struct in named tok by encoding/json. The
+// fields of embedded structs are searched a level at a time, so as when
+// marshaling the shallowest field wins and a tagged field wins over untagged
+// ones at the same depth, while any other conflict leaves no field.
+func (r resolver) jsonField(in reflect.Value, tok string) (reflect.Value, error) {
+	seen := map[reflect.Type]bool{in.Type(): true}
+	for level := []reflect.Value{in}; len(level) > 0; {
+		var next []reflect.Value
+		var res reflect.Value
+		var found, tagged int
+		for _, sv := range level {
+			err := r.IterStruct(sv, func(sf reflect.StructField, v reflect.Value) error {
+				name, ok := jsonName(sf)
+				switch {
+				case !ok:
+				case len(name) == 0:
+					if v = reflect.Indirect(v); v.IsValid() && !seen[v.Type()] {
+						next = append(next, v)
+					}
+				case name == tok && v.CanInterface():
+					if found++; jsonTagged(sf) {
+						tagged++
+						res = v
+					} else if tagged == 0 {
+						res = v
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return reflect.Value{}, err
+			}
+		}
+		switch {
+		case tagged == 1, found == 1:
+			return res, nil
+		case found > 1:
+			return reflect.Value{}, errors.New("ambiguous field")
+		}
+		for _, v := range next {
+			seen[v.Type()] = true
+		}
+		level = next
+	}
+	return reflect.Value{}, errors.New("no such field")
+}
+
+func (r resolver) index(in reflect.Value, idx int) (reflect.Value, error) {
+	if idx < 0 || idx >= in.Len() {
+		return reflect.Value{}, fmt.Errorf("index %d out of range [0:%d]",
+			idx, in.Len())
+	}
+	var res reflect.Value
+	err := r.IterSlice(in, func(i int, v reflect.Value) error {
+		if i != idx {
+			return nil
+		}
+		res = v
+		return errResolved
+	})
+	return r.result(res, err, "no such index")
+}
+
+func (r resolver) key(in reflect.Value,
+	match func(key reflect.Value) bool) (reflect.Value, error) {
+	var res reflect.Value
+	err := r.IterMap(in, func(k, v reflect.Value) error {
+		if !k.CanInterface() || !v.CanInterface() || !match(k) {
+			return nil
+		}
+		res = v
+		return errResolved
+	})
+	return r.result(res, err, "no such key")
+}
+
+func (r resolver) result(res reflect.Value, err error,
+	msg string) (reflect.Value, error) {
+	if err != nil && err != errResolved {
+		return reflect.Value{}, err
+	}
+	if !res.IsValid() {
+		return reflect.Value{}, errors.New(msg)
+	}
+	return res, nil
+}
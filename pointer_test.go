@@ -0,0 +1,171 @@
+package iter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONPointer(t *testing.T) {
+	type testPointerMeta struct {
+		Meta   map[string]string `json:"meta,omitempty"`
+		Ignore int               `json:"-"`
+		Plain  []int
+	}
+	type TestPointerEmbed struct {
+		A int `json:"a"`
+		B int
+	}
+	type TestPointerTagged struct {
+		C int
+	}
+	type testPointer struct {
+		Packages []testPointerMeta `json:"packages"`
+		Nested   map[int]*testPointerMeta
+		Iface    interface{}
+		TestPointerEmbed
+		TestPointerTagged `json:"tagged"`
+		B                 int `json:"b"`
+	}
+	give := &testPointer{
+		Packages: []testPointerMeta{
+			{}, {},
+			{
+				Meta:   map[string]string{"owner": "a", "a/b~c": "b"},
+				Ignore: 1,
+				Plain:  []int{1, 2},
+			},
+		},
+		Nested: map[int]*testPointerMeta{3: {Plain: []int{3}}},
+		Iface:  []interface{}{map[string]int{"": 4}},
+
+		TestPointerEmbed:  TestPointerEmbed{A: 5, B: 6},
+		TestPointerTagged: TestPointerTagged{C: 7},
+		B:                 8,
+	}
+
+	t.Run("Walk", func(t *testing.T) {
+		res := make(map[string]interface{})
+		var ignored []string
+		err := Walk(give, func(el Pair) error {
+			ptr, ok := el.Path().JSONPointer()
+			if !ok {
+				ignored = append(ignored, el.Path().String())
+				return nil
+			}
+			if _, ok := res[ptr]; ok {
+				t.Fatalf("expected unique pointer, got duplicate: %v", ptr)
+			}
+			res[ptr] = el.Val()
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		exp := map[string]interface{}{
+			`/packages/2/meta/owner`:   "a",
+			`/packages/2/meta/a~1b~0c`: "b",
+			`/packages/2/Plain/0`:      1,
+			`/packages/2/Plain/1`:      2,
+			`/Nested/3/Plain/0`:        3,
+			`/Iface/0/`:                4,
+			`/a`:                       5,
+			`/B`:                       6,
+			`/tagged/C`:                7,
+			`/b`:                       8,
+		}
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+		expIgnored := []string{
+			`.Packages[0].Ignore`, `.Packages[1].Ignore`, `.Packages[2].Ignore`,
+			`.Nested[3].Ignore`,
+		}
+		if !reflect.DeepEqual(expIgnored, ignored) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", expIgnored, ignored)
+		}
+		for ptr, exp := range exp {
+			got, err := ResolvePointer(give, ptr)
+			if err != nil {
+				t.Fatalf("expected nil err for %v, got: %v", ptr, err)
+			}
+			if !reflect.DeepEqual(exp, got) {
+				t.Errorf("DeepEqual failed for %v:\n  exp: %#v\n  got: %#v", ptr, exp, got)
+			}
+		}
+	})
+
+	t.Run("Root", func(t *testing.T) {
+		if got, ok := Path(nil).JSONPointer(); got != "" || !ok {
+			t.Errorf("expected empty pointer, got: %v %v", got, ok)
+		}
+		got, err := ResolvePointer(give, "")
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if got != give {
+			t.Errorf("expected root value, got: %v", got)
+		}
+	})
+
+	t.Run("Resolve", func(t *testing.T) {
+		tests := []struct {
+			ptr    string
+			exp    interface{}
+			errStr string
+		}{
+			{`/packages/2/meta`, give.Packages[2].Meta, ``},
+			{`/Nested/3`, give.Nested[3], ``},
+			{`/packages/2/Plain`, []int{1, 2}, ``},
+			{`packages`, nil, `must begin with a '/'`},
+			{`/Packages`, nil, `token "Packages": no such field`},
+			{`/packages/3`, nil, `index 3 out of range [0:3]`},
+			{`/packages/-`, nil, `nonexistent element`},
+			{`/packages/01`, nil, `invalid array index "01"`},
+			{`/packages/a`, nil, `invalid array index "a"`},
+			{`/Nested/4`, nil, `no such key`},
+			{`/packages/2/Plain/0/a`, nil, `expected structured kind, not int`},
+			{`/packages/2/Ignore`, nil, `token "Ignore": no such field`},
+			{`/packages/2/-`, nil, `token "-": no such field`},
+			{`/TestPointerEmbed`, nil, `no such field`},
+			{`/TestPointerEmbed/a`, nil, `no such field`},
+			{`/tagged`, give.TestPointerTagged, ``},
+			{`/C`, nil, `no such field`},
+		}
+		for _, tc := range tests {
+			got, err := ResolvePointer(give, tc.ptr)
+			if err := tchkstr(t, err, tc.errStr); err != nil {
+				t.Errorf("%v: %v", tc.ptr, err)
+				continue
+			}
+			if !reflect.DeepEqual(tc.exp, got) {
+				t.Errorf("DeepEqual failed for %v:\n  exp: %#v\n  got: %#v", tc.ptr, tc.exp, got)
+			}
+		}
+	})
+	t.Run("Embedded", func(t *testing.T) {
+		type TestPointerX struct{ X, Y int }
+		type TestPointerY struct {
+			X int
+			Y int `json:"Y"`
+		}
+		type TestPointerZ struct{ *TestPointerZ }
+		give := struct {
+			TestPointerX
+			*TestPointerY
+			TestPointerZ
+		}{TestPointerX{1, 2}, &TestPointerY{3, 4}, TestPointerZ{}}
+
+		if _, err := ResolvePointer(give, `/X`); err == nil ||
+			!strings.Contains(err.Error(), "ambiguous field") {
+			t.Fatalf("expected ambiguous field err, got: %v", err)
+		}
+		got, err := ResolvePointer(give, `/Y`)
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if got != 4 {
+			t.Fatalf("expected tagged field to win, got: %v", got)
+		}
+	})
+}
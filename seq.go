@@ -0,0 +1,92 @@
+//go:build go1.23
+
+package iter
+
+import (
+	"errors"
+	goiter "iter"
+	"reflect"
+)
+
+// errBreak is returned to a Walker or Iterator to halt it once the body of a
+// range loop over a sequence has broken out early.
+var errBreak = errors.New("break")
+
+// Seq returns a sequence of each Pair visited by Walk for use with a range
+// loop, along with a func that returns any error ending the most recent range
+// over it. Breaking out of the loop halts the walk, which is not considered an
+// error.
+func Seq(value interface{}) (goiter.Seq[Pair], func() error) {
+	return SeqWalker(defaultWalker, value)
+}
+
+// SeqWalker is like Seq but visits the Pairs of the given Walker.
+func SeqWalker(w Walker, value interface{}) (goiter.Seq[Pair], func() error) {
+	var err error
+	seq := func(yield func(Pair) bool) {
+		err = w.Walk(value, func(el Pair) error {
+			if !yield(el) {
+				return errBreak
+			}
+			return nil
+		})
+		if err == errBreak {
+			err = nil
+		}
+	}
+	return seq, func() error { return err }
+}
+
+// SeqSlice returns a sequence of each index and element visited by IterSlice
+// of the given Iterator, see Seq for details.
+func SeqSlice(it Iterator, val reflect.Value) (
+	goiter.Seq2[int, reflect.Value], func() error) {
+	return seq2(func(f func(int, reflect.Value) error) error {
+		return it.IterSlice(val, f)
+	})
+}
+
+// SeqMap returns a sequence of each key and value visited by IterMap of the
+// given Iterator, see Seq for details.
+func SeqMap(it Iterator, val reflect.Value) (
+	goiter.Seq2[reflect.Value, reflect.Value], func() error) {
+	return seq2(func(f func(reflect.Value, reflect.Value) error) error {
+		return it.IterMap(val, f)
+	})
+}
+
+// SeqStruct returns a sequence of each field and value visited by IterStruct
+// of the given Iterator, see Seq for details.
+func SeqStruct(it Iterator, val reflect.Value) (
+	goiter.Seq2[reflect.StructField, reflect.Value], func() error) {
+	return seq2(func(f func(reflect.StructField, reflect.Value) error) error {
+		return it.IterStruct(val, f)
+	})
+}
+
+// SeqChan returns a sequence of each sequence number and value received by
+// IterChan of the given Iterator, see Seq for details.
+func SeqChan(it Iterator, val reflect.Value) (
+	goiter.Seq2[int, reflect.Value], func() error) {
+	return seq2(func(f func(int, reflect.Value) error) error {
+		return it.IterChan(val, f)
+	})
+}
+
+// seq2 adapts an iteration method of an Iterator to a sequence.
+func seq2[K, V any](iterate func(f func(K, V) error) error) (
+	goiter.Seq2[K, V], func() error) {
+	var err error
+	seq := func(yield func(K, V) bool) {
+		err = iterate(func(k K, v V) error {
+			if !yield(k, v) {
+				return errBreak
+			}
+			return nil
+		})
+		if err == errBreak {
+			err = nil
+		}
+	}
+	return seq, func() error { return err }
+}
@@ -0,0 +1,156 @@
+//go:build go1.23
+
+package iter
+
+import (
+	"reflect"
+	"testing"
+)
+
+// countIter counts the elements visited by the embedded Iterator.
+type countIter struct {
+	Iterator
+	n int
+}
+
+func (it *countIter) IterSlice(val reflect.Value,
+	f func(idx int, val reflect.Value) error) error {
+	return it.Iterator.IterSlice(val, func(idx int, val reflect.Value) error {
+		it.n++
+		return f(idx, val)
+	})
+}
+
+func TestSeq(t *testing.T) {
+	give := []interface{}{1, []int{2, 3}, map[string]int{"a": 4}}
+
+	t.Run("Walk", func(t *testing.T) {
+		var exp []interface{}
+		Walk(give, func(el Pair) error {
+			exp = append(exp, el.Val())
+			return nil
+		})
+
+		seq, errf := Seq(give)
+		for i := 0; i < 2; i++ {
+			var res []interface{}
+			for el := range seq {
+				res = append(res, el.Val())
+			}
+			if err := errf(); err != nil {
+				t.Fatalf("expected nil err, got: %v", err)
+			}
+			if !reflect.DeepEqual(exp, res) {
+				t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+			}
+		}
+	})
+
+	t.Run("WalkBreak", func(t *testing.T) {
+		it := &countIter{Iterator: NewIter()}
+		seq, errf := SeqWalker(NewWalker(it), give)
+		for el := range seq {
+			if el.Val() == 2 {
+				break
+			}
+		}
+		if err := errf(); err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if it.n != 3 {
+			t.Fatalf("expected walk to halt after 3 elements, got: %v", it.n)
+		}
+	})
+
+	t.Run("WalkError", func(t *testing.T) {
+		seq, errf := SeqWalker(NewWalker(errIter{NewIter()}), give)
+		for el := range seq {
+			t.Fatalf("expected no pairs, got: %v", el)
+		}
+		if err := errf(); err == nil || err.Error() != "iteration failed" {
+			t.Fatalf("expected iteration failed err, got: %v", err)
+		}
+	})
+
+	t.Run("Slice", func(t *testing.T) {
+		it := &countIter{Iterator: NewIter()}
+		seq, errf := SeqSlice(it, reflect.ValueOf([]string{"a", "b", "c"}))
+		var res []string
+		for i, v := range seq {
+			res = append(res, v.String())
+			if i == 1 {
+				break
+			}
+		}
+		if err := errf(); err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if exp := []string{"a", "b"}; !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+		if it.n != 2 {
+			t.Fatalf("expected iteration to halt after 2 elements, got: %v", it.n)
+		}
+
+		seq, errf = SeqSlice(it, reflect.ValueOf(1))
+		for i := range seq {
+			t.Fatalf("expected no elements, got: %v", i)
+		}
+		if err := errf(); err == nil {
+			t.Fatal("expected non-nil err")
+		}
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		seq, errf := SeqMap(&Iter{SortMapKeys: true},
+			reflect.ValueOf(map[string]int{"b": 2, "a": 1, "c": 3}))
+		var res []string
+		for k := range seq {
+			res = append(res, k.String())
+		}
+		if err := errf(); err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if exp := []string{"a", "b", "c"}; !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("Struct", func(t *testing.T) {
+		v := reflect.ValueOf(struct{ A, B int }{1, 2})
+		seq, errf := SeqStruct(NewIter(), v)
+		var res []string
+		for field, v := range seq {
+			res = append(res, field.Name)
+			if v.Int() == 1 {
+				break
+			}
+		}
+		if err := errf(); err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if exp := []string{"A"}; !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("Chan", func(t *testing.T) {
+		ch := make(chan int, 3)
+		ch <- 1
+		ch <- 2
+		ch <- 3
+		seq, errf := SeqChan(&Iter{ChanRecv: true}, reflect.ValueOf(ch))
+		for seq, v := range seq {
+			if seq != 0 || v.Int() != 1 {
+				t.Fatalf("expected first receive, got: %v %v", seq, v)
+			}
+			break
+		}
+		if err := errf(); err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if len(ch) != 2 {
+			t.Fatalf("expected 2 values left in chan, got: %v", len(ch))
+		}
+	})
+}
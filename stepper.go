@@ -0,0 +1,138 @@
+package iter
+
+// stepEvent describes how a Pair returned by stepper.step is to be visited.
+type stepEvent int
+
+const (
+	stepDone stepEvent = iota
+	stepLeaf
+	stepEnter
+	stepExit
+)
+
+// stepper performs a depth first walk one Pair at a time, keeping an explicit
+// stack of frames rather than recursing so the depth of a value is limited
+// only by memory. An Iterator can't be suspended while it visits a structured
+// value, so each frame holds the children of a value collected when it is
+// entered. Pairs are produced in the same order dfsWalker.walk visits them.
+type stepper struct {
+	w       *walker
+	stack   []frame
+	pending Pair
+
+	// done is released at the following step when pooling, once the walk func
+	// is finished with it.
+	done Pair
+}
+
+// frame holds a structured value that has been entered and the children that
+// have yet to be visited.
+type frame struct {
+	el       Pair
+	children []Pair
+}
+
+func newStepper(w *walker, root Pair) *stepper {
+	w.refs = make(map[ref]Pair)
+	return &stepper{w: w, pending: root}
+}
+
+// step returns the next Pair of the walk and how it is to be visited, or
+// stepDone once no Pairs remain or the walk is canceled. When stepEnter is
+// returned descend must be called before the next step.
+func (s *stepper) step() (stepEvent, Pair, error) {
+	if s.done != nil {
+		s.w.release(s.done)
+		s.done = nil
+	}
+	for s.pending == nil {
+		n := len(s.stack)
+		if n == 0 {
+			return stepDone, nil, nil
+		}
+		top := &s.stack[n-1]
+		if len(top.children) == 0 {
+			return stepExit, s.pop(), nil
+		}
+		s.pending = top.children[0]
+		top.children[0] = nil
+		top.children = top.children[1:]
+		if err := s.w.canceled(s.pending); err != nil {
+			return stepDone, nil, err
+		}
+	}
+
+	el := s.pending
+	s.pending = nil
+	if _, ok := s.w.structured(el); !ok {
+		s.finish(el)
+		return stepLeaf, el, nil
+	}
+	return stepEnter, el, nil
+}
+
+// descend is given the error returned when entering el and pushes a frame for
+// it holding each of its children, or none if they are skipped. Errors other
+// than SkipChildren are returned without entering el.
+func (s *stepper) descend(el Pair, err error) error {
+	if err != nil && err != SkipChildren {
+		if err == SkipSiblings {
+			s.finish(el)
+		}
+		return err
+	}
+
+	s.push(el)
+
+	// The value may have been replaced through Set.
+	in, ok := s.w.structured(el)
+	if err != nil || !ok {
+		return nil
+	}
+	top := &s.stack[len(s.stack)-1]
+	return s.w.expand(el, in, func(child Pair) error {
+		top.children = append(top.children, child)
+		return nil
+	})
+}
+
+// skipSiblings drops the children of the innermost frame that have not been
+// visited, so that it is exited by the next step.
+func (s *stepper) skipSiblings() {
+	n := len(s.stack)
+	if n == 0 {
+		return
+	}
+	top := &s.stack[n-1]
+	for _, child := range top.children {
+		s.w.release(child)
+	}
+	top.children = nil
+}
+
+func (s *stepper) push(el Pair) {
+	s.stack = append(s.stack, frame{el: el})
+	if pr, ok := el.(*pair); ok && pr.ref.ptr != 0 {
+		s.w.refs[pr.ref] = el
+	}
+}
+
+func (s *stepper) pop() Pair {
+	n := len(s.stack) - 1
+	el := s.stack[n].el
+	s.stack[n] = frame{}
+	s.stack = s.stack[:n]
+	if pr, ok := el.(*pair); ok && pr.ref.ptr != 0 {
+		delete(s.w.refs, pr.ref)
+	}
+	s.finish(el)
+	return el
+}
+
+// finish marks el as visited for the last time, the root is never released
+// since it was not taken from the walker.
+func (s *stepper) finish(el Pair) {
+	if el.Parent() != nil {
+		s.done = el
+	}
+}
@@ -0,0 +1,32 @@
+package iter
+
+import (
+	"context"
+)
+
+// Stream walks value like WalkContext on a new Go routine, sending each Pair on
+// the returned Pair channel. The channel is unbuffered, so the walk advances
+// only as fast as Pairs are received. Once the walk ends the Pair channel is
+// closed, then any error that ended it is sent on the error channel before it
+// is closed as well. When the context is done the walk stops with a PathError
+// holding ctx.Err(), so a consumer which stops receiving Pairs early must
+// cancel the context to release the Go routine.
+func Stream(ctx context.Context, value interface{}) (<-chan Pair, <-chan error) {
+	pairs, errs := make(chan Pair), make(chan error, 1)
+	go func() {
+		defer close(errs)
+		err := WalkContext(ctx, value, func(el Pair) error {
+			select {
+			case pairs <- el:
+				return nil
+			case <-ctx.Done():
+				return &PathError{Path: el.Path(), Err: ctx.Err()}
+			}
+		})
+		close(pairs)
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return pairs, errs
+}
@@ -0,0 +1,79 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestStream(t *testing.T) {
+	give := map[string]interface{}{
+		"a": []int{1, 2, 3},
+		"b": map[string]string{"c": "d"},
+		"e": struct{ F, G int }{4, 5},
+	}
+
+	t.Run("Complete", func(t *testing.T) {
+		exp := make(map[string]interface{})
+		Walk(give, func(el Pair) error {
+			exp[el.Path().String()] = el.Val()
+			return nil
+		})
+
+		res := make(map[string]interface{})
+		pairs, errs := Stream(context.Background(), give)
+		for el := range pairs {
+			res[el.Path().String()] = el.Val()
+		}
+		if err := <-errs; err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if _, ok := <-errs; ok {
+			t.Fatal("expected error channel to be closed")
+		}
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		pairs, errs := Stream(ctx, give)
+		for el := range pairs {
+			t.Fatalf("expected no pairs, got: %v", el)
+		}
+		var pe *PathError
+		if err := <-errs; !errors.As(err, &pe) || len(pe.Path) != 0 {
+			t.Fatalf("expected PathError at root, got: %v", err)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+		pairs, errs := Stream(ctx, give)
+		<-pairs
+		cancel()
+
+		var pe *PathError
+		if err := <-errs; !errors.As(err, &pe) || pe.Err != context.Canceled {
+			t.Fatalf("expected PathError holding context.Canceled, got: %v", err)
+		}
+		for range pairs {
+			t.Fatal("expected pair channel to be closed")
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if n := runtime.NumGoroutine(); n > before {
+			t.Fatalf("expected %v goroutines, got: %v", before, n)
+		}
+	})
+}
@@ -0,0 +1,12 @@
+package iter
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// unexportedValue returns a Value of the addressable unexported struct field
+// val which may be interfaced and set, for Iter.AllowUnexported.
+func unexportedValue(val reflect.Value) reflect.Value {
+	return reflect.NewAt(val.Type(), unsafe.Pointer(val.UnsafeAddr())).Elem()
+}
@@ -3,6 +3,7 @@ package iter
 import (
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 // Functions that are not worth an import dependency are in here, they come from
@@ -39,6 +40,173 @@ func indirect(value interface{}) interface{} {
 	}
 }
 
+// indirectValue is like indirect but operates on reflect.Values so that they
+// remain addressable, following interfaces as well as pointers.
+func indirectValue(val reflect.Value) reflect.Value {
+	for {
+		switch val.Kind() {
+		case reflect.Interface:
+			if val.IsNil() {
+				return val
+			}
+			val = val.Elem()
+		case reflect.Ptr:
+			if val.IsNil() {
+				return val
+			}
+
+			// Test for a circular type.
+			res := val.Elem()
+			if res.Kind() == reflect.Ptr && val.Pointer() == res.Pointer() {
+				return val
+			}
+			val = res
+		default:
+			return val
+		}
+	}
+}
+
+// valueOf returns a Value of typ holding value that is not addressable, typ must
+// be the type of value or an interface it implements.
+func valueOf(value interface{}, typ reflect.Type) reflect.Value {
+	if typ.Kind() != reflect.Interface {
+		return reflect.ValueOf(value)
+	}
+	if value == nil {
+		return reflect.Zero(typ)
+	}
+	return reflect.ValueOf(value).Convert(typ)
+}
+
+// compareValues returns -1, 0 or 1 if a is less than, equal to or greater than
+// b. It orders values the same way package fmt sorts map keys, which it does
+// using the internal package fmtsort:
+//
+//   - ints, floats, and strings are ordered by <
+//   - NaN compares less than non-NaN floats
+//   - bool compares false before true
+//   - complex compares real, then imag
+//   - pointers and channels compare by machine address
+//   - structs and arrays compare each field or element in turn
+//   - interface values compare first by reflect.Type describing the concrete
+//     type and then by concrete value as described above
+//
+// Both values are expected to be of the same type, as map keys are.
+func compareValues(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareInt(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return compareUint(a.Uint(), b.Uint())
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Float32, reflect.Float64:
+		return compareFloat(a.Float(), b.Float())
+	case reflect.Complex64, reflect.Complex128:
+		ac, bc := a.Complex(), b.Complex()
+		if c := compareFloat(real(ac), real(bc)); c != 0 {
+			return c
+		}
+		return compareFloat(imag(ac), imag(bc))
+	case reflect.Bool:
+		switch ab, bb := a.Bool(), b.Bool(); {
+		case ab == bb:
+			return 0
+		case ab:
+			return 1
+		default:
+			return -1
+		}
+	case reflect.Ptr, reflect.UnsafePointer, reflect.Chan:
+		if c, ok := compareNil(a, b); ok {
+			return c
+		}
+		return compareUint(uint64(a.Pointer()), uint64(b.Pointer()))
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if c := compareValues(a.Field(i), b.Field(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if c := compareValues(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Interface:
+		if c, ok := compareNil(a, b); ok {
+			return c
+		}
+		at, bt := reflect.ValueOf(a.Elem().Type()), reflect.ValueOf(b.Elem().Type())
+		if c := compareValues(at, bt); c != 0 {
+			return c
+		}
+		return compareValues(a.Elem(), b.Elem())
+	default:
+		// Map keys can't be of any other kind.
+		return 0
+	}
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	case a == b:
+		return 0
+	case a != a && b != b:
+		return 0
+	case a != a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// compareNil orders nil values before non-nil ones, ok is false if neither
+// value is nil.
+func compareNil(a, b reflect.Value) (int, bool) {
+	switch an, bn := a.IsNil(), b.IsNil(); {
+	case an && bn:
+		return 0, true
+	case an:
+		return -1, true
+	case bn:
+		return 1, true
+	default:
+		return 0, false
+	}
+}
+
 // recoverFn will attempt to execute f, if f return a non-nil error it will be
 // returned. If f panics this function will attempt to recover() and return a
 // error instead.
@@ -2,6 +2,7 @@ package iter
 
 import (
 	"fmt"
+	"math"
 	"reflect"
 	"runtime"
 	"testing"
@@ -106,3 +107,42 @@ func TestRecoverFn(t *testing.T) {
 		}
 	})
 }
+
+func TestCompareValues(t *testing.T) {
+	nan := math.NaN()
+	x := 1
+	tests := []struct {
+		a, b interface{}
+		exp  int
+	}{
+		{1, 2, -1}, {2, 1, 1}, {2, 2, 0},
+		{uint(1), uint(2), -1}, {"b", "a", 1}, {"a", "a", 0},
+		{false, true, -1}, {true, false, 1}, {true, true, 0},
+		{nan, 1.0, -1}, {1.0, nan, 1}, {nan, nan, 0}, {math.Inf(-1), nan, 1},
+		{complex(1, 2), complex(1, 3), -1}, {complex(2, 0), complex(1, 3), 1},
+		{[2]int{1, 2}, [2]int{1, 3}, -1}, {[2]int{1, 2}, [2]int{1, 2}, 0},
+		{struct{ A, B int }{1, 2}, struct{ A, B int }{1, 1}, 1},
+		{(*int)(nil), &x, -1}, {&x, (*int)(nil), 1}, {&x, &x, 0},
+	}
+	for _, tc := range tests {
+		got := compareValues(reflect.ValueOf(tc.a), reflect.ValueOf(tc.b))
+		if got != tc.exp {
+			t.Errorf("compareValues(%v, %v) failed:\n  exp: %v\n  got: %v",
+				tc.a, tc.b, tc.exp, got)
+		}
+	}
+
+	t.Run("Interface", func(t *testing.T) {
+		ifaces := []interface{}{nil, 1, "a"}
+		v := reflect.ValueOf(ifaces)
+		if got := compareValues(v.Index(0), v.Index(1)); got != -1 {
+			t.Errorf("expected nil interface to compare less, got: %v", got)
+		}
+		if got := compareValues(v.Index(1), v.Index(1)); got != 0 {
+			t.Errorf("expected equal interfaces to compare equal, got: %v", got)
+		}
+		if got := compareValues(v.Index(1), v.Index(2)); got == 0 {
+			t.Errorf("expected interfaces of different types to differ, got: %v", got)
+		}
+	})
+}
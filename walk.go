@@ -1,20 +1,68 @@
 package iter
 
-import "reflect"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// SkipChildren may be returned by a walk func when given a Pair holding a
+	// structured value to prevent the Walker from walking its children. It is
+	// treated the same as a nil error for any other Pair and is never returned
+	// by a Walker.
+	SkipChildren = errors.New("skip children")
+
+	// SkipSiblings may be returned by a walk func to end iteration of the
+	// structured value that holds the current Pair, the Walker then continues
+	// with the next element of the parents structured value. It is never
+	// returned by a Walker.
+	SkipSiblings = errors.New("skip siblings")
+)
 
 // Walk will recursively walk the given interface value as long as an error does
 // not occur. The pair func will be given a interface value for each value
 // visited during walking and is expected to return an error if it thinks the
-// traversal should end. A nil value and error is given to the walk func if an
-// inaccessible value (can't reflect.Interface()) is found.
+// traversal should end. Inaccessible values (can't reflect.Interface()) are
+// skipped, see ReportInaccessible for a Walker that visits them.
 //
 // Walk is called on each element of maps, slices and arrays. If the underlying
 // iterator is configured for channels it receives until one fails. Channels
 // should probably be avoided as ranging over them is more concise.
+//
+// When value is a pointer each Pair given to the walk func may be used to
+// modify the element it was retrieved from with Set.
+//
+// Pointers, maps and slices that refer back to a value currently being walked
+// are not descended into again, instead the walk func is given a Pair holding
+// a CycleError.
+//
+// The walk func may return SkipSiblings to stop visiting the current structured
+// value without halting the entire walk.
 func Walk(value interface{}, f func(el Pair) error) error {
 	return defaultWalker.Walk(value, f)
 }
 
+// WalkContext is like Walk but stops once the given context is done. See
+// ContextWalker for details.
+func WalkContext(ctx context.Context, value interface{},
+	f func(el Pair) error) error {
+	return defaultWalker.WalkContext(ctx, value, f)
+}
+
+// Walkable is implemented by values which enumerate their own children, such
+// as trees or linked lists that would otherwise be walked through their
+// internal fields. Walkers call Walk for each visited value that implements
+// it, directly or through a pointer, rather than using their Iterator. Walk
+// should call f with a Pair for each child, created with NewPair using parent
+// as the Parent, and return the first non-nil error f returns.
+type Walkable interface {
+	Walk(parent Pair, f func(el Pair) error) error
+}
+
+var walkableType = reflect.TypeOf((*Walkable)(nil)).Elem()
+
 // A Walker is used to perform a full traversal of each child value of any Go
 // type. Each implementation may use their own algorithm for traversal, giving
 // no guarantee for the order each element is visited in.
@@ -22,74 +70,689 @@ type Walker interface {
 	Walk(value interface{}, f func(el Pair) error) error
 }
 
+// A ContextWalker is a Walker which may be interrupted by a context. The
+// context is checked before visiting each element of a structured value and
+// channel receives are interrupted when it's done, provided the Iterator
+// implements ContextIterator. When the walk is interrupted a PathError is
+// returned holding ctx.Err() and the Path of the element being visited. Each
+// Walker returned by this package implements ContextWalker.
+type ContextWalker interface {
+	Walker
+	WalkContext(ctx context.Context, value interface{},
+		f func(el Pair) error) error
+}
+
+// PathError records an error that halted a walk and the Path of the element
+// that was being visited.
+type PathError struct {
+	Path Path
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	if len(e.Path) == 0 {
+		return fmt.Sprintf("walk stopped at root: %v", e.Err)
+	}
+	return fmt.Sprintf("walk stopped at %v: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// Traverse will walk the given value like Walk, calling the funcs in fns as
+// each value is visited. See WalkFuncs for details.
+func Traverse(value interface{}, fns WalkFuncs) error {
+	return defaultWalker.Traverse(value, fns)
+}
+
+// WalkFuncs holds the funcs called by a Traverser. Enter is called with each
+// Pair holding a structured value before its children are visited and Exit
+// after. Leaf is called with each Pair that does not hold a structured value.
+// Any of them may be nil, in which case that kind of visit is ignored.
+//
+// Exit is called for each structured value that Enter returned a nil error or
+// SkipChildren for, making the events suitable for building serializers or
+// tree printers. A SkipSiblings error returned from Enter skips the children
+// of the Pair as well as its siblings.
+type WalkFuncs struct {
+	Enter func(el Pair) error
+	Leaf  func(el Pair) error
+	Exit  func(el Pair) error
+}
+
+// A Traverser walks values like a Walker while reporting when it enters and
+// exits structured values. The Walker returned from NewWalker implements
+// Traverser.
+type Traverser interface {
+	Traverse(value interface{}, fns WalkFuncs) error
+}
+
+// CycleError is held by a Pair whose value refers to the same pointer, map or
+// slice as one of its ancestors. Walking into it would never terminate, so the
+// Pair is given to the walk func as is.
+type CycleError struct {
+
+	// Ancestor is the Pair holding the value that was referred to.
+	Ancestor Pair
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("cycle detected: value refers to ancestor %v at depth %d",
+		e.Ancestor, e.Ancestor.Depth())
+}
+
+// InaccessibleError is held by a Pair whose value could not be obtained by
+// the Walker, because it is invalid or retrieved from an unexported struct
+// field. It is only visited by Walkers configured with ReportInaccessible.
+type InaccessibleError struct {
+
+	// Name is the struct field name, or the formatted index or map key of
+	// the inaccessible value.
+	Name string
+
+	// Kind is the kind of the inaccessible value, reflect.Invalid if the
+	// Iterator did not provide a valid reflect.Value.
+	Kind reflect.Kind
+
+	// Path is the location of the parent Pair holding the value.
+	Path Path
+}
+
+func (e *InaccessibleError) Error() string {
+	if len(e.Path) == 0 {
+		return fmt.Sprintf("inaccessible %s value %v", e.Kind, e.Name)
+	}
+	return fmt.Sprintf("inaccessible %s value %v in %v", e.Kind, e.Name, e.Path)
+}
+
+// A WalkerOption configures optional behavior of a Walker.
+type WalkerOption func(w *walker)
+
+// VisitContainers causes the Walker to call the walk func for each structured
+// value (maps, slices, arrays, structs and chans) before visiting its children,
+// which is required for SkipChildren to have any effect. By default the walk
+// func is only called for values that are not structured.
+func VisitContainers() WalkerOption {
+	return func(w *walker) {
+		w.containers = true
+	}
+}
+
+// SkipCycles causes the Walker to silently skip values that would begin a
+// cycle, instead of visiting a Pair holding a CycleError.
+func SkipCycles() WalkerOption {
+	return func(w *walker) {
+		w.skipCycles = true
+	}
+}
+
+// ReportInaccessible causes the Walker to visit values it can not access with
+// a Pair holding a nil value and an InaccessibleError, instead of skipping
+// them. Fields excluded by the Iterator are never visited.
+func ReportInaccessible() WalkerOption {
+	return func(w *walker) {
+		w.inaccessible = true
+	}
+}
+
+// MaxDepth limits the Walker to values with a Depth() of at most n. Structured
+// values at the limit are treated as if they were not structured, so they are
+// given to the walk func without visiting their children and Truncated() will
+// return true. A n of zero or less walks without limit.
+func MaxDepth(n int) WalkerOption {
+	return func(w *walker) {
+		w.maxDepth = n
+	}
+}
+
+// PoolPairs causes the Walker to reuse each Pair once the walk func has
+// returned for it and all of its children, so a walk allocates Pairs in
+// proportion to the depth of the value rather than its size. Pairs, and any
+// Pair held by their errors, are then only valid until the walk func returns
+// and must be copied with Clone to be retained. Pairs that a walk func
+// returned an error for are never reused. Walkers that visit each Pair of a
+// level before walking any children, such as NewBFSWalker, ignore this option.
+func PoolPairs() WalkerOption {
+	return func(w *walker) {
+		w.pool = true
+	}
+}
+
+// Iterative causes the Walker to keep its own stack of the structured values
+// it has entered rather than recursing through them, so values of any depth,
+// such as very long linked lists, may be walked without exhausting the stack
+// of the goroutine. Pairs are visited in the same order, but each structured
+// value is fully iterated before its children are visited, so channels are
+// received from until they fail first and every child of the values being
+// walked is held in memory at once. It has no effect on NewBFSWalker.
+func Iterative() WalkerOption {
+	return func(w *walker) {
+		w.iterative = true
+	}
+}
+
+// Opaque causes the Walker to treat values of the same type as any of the
+// given values as if they were not structured, so a single Pair is visited for
+// them rather than one for each of their internal fields or elements. Values
+// pointing to them are opaque as well. To register an interface pass a nil
+// pointer to it, such as (*fmt.Stringer)(nil), which makes each type that
+// implements it opaque, including those that only do so through a pointer.
+// Opaque types are never given to the Walk method of a Walkable.
+func Opaque(values ...interface{}) WalkerOption {
+	return func(w *walker) {
+		if w.opaque == nil {
+			w.opaque = &opaqueTypes{types: make(map[reflect.Type]bool)}
+		}
+		for _, value := range values {
+			w.opaque.add(value)
+		}
+	}
+}
+
+// DefaultOpaque is Opaque with common standard library types that hold a
+// single value, but whose internals would be walked otherwise. These include
+// time.Time, big.Int, net.IP, netip.Addr, url.URL, regexp.Regexp and the
+// synchronization primitives of package sync such as sync.Mutex.
+func DefaultOpaque() WalkerOption {
+	return Opaque(defaultOpaque...)
+}
+
 // NewWalker returns a new Walker backed by the given Iterator. It will use a
 // basic dfs traversal and will not visit items that can not be converted to an
 // interface.
-func NewWalker(iterator Iterator) Walker {
-	return &dfsWalker{Iterator: iterator}
+func NewWalker(iterator Iterator, opts ...WalkerOption) Walker {
+	return &dfsWalker{newWalker(iterator, opts)}
+}
+
+// NewBFSWalker returns a new Walker backed by the given Iterator. It will use a
+// bfs traversal, visiting every Pair at a given Depth() before any Pair that is
+// nested deeper. This is useful for finding the shallowest match in a large
+// value without first descending into deep branches. Like NewWalker it will
+// not visit items that can not be converted to an interface.
+//
+// Each structured value is fully iterated before its children are walked, so
+// it holds a reference to each Pair of the widest level in memory.
+func NewBFSWalker(iterator Iterator, opts ...WalkerOption) Walker {
+	return &bfsWalker{newWalker(iterator, opts)}
 }
 
 type dfsWalker struct {
-	Iterator
+	walker
 }
 
 func (w dfsWalker) Walk(value interface{}, f func(el Pair) error) error {
-	root := &pair{
+	fns := WalkFuncs{Leaf: f}
+	if w.containers {
+		fns.Enter = f
+	}
+	return w.Traverse(value, fns)
+}
+
+func (w dfsWalker) WalkContext(ctx context.Context, value interface{},
+	f func(el Pair) error) error {
+	w.ctx = ctx
+	return w.Walk(value, f)
+}
+
+func (w dfsWalker) Traverse(value interface{}, fns WalkFuncs) error {
+	root := newRootPair(value)
+	if err := w.canceled(root); err != nil {
+		return err
+	}
+	var err error
+	if w.iterative {
+		err = w.walkStack(root, fns)
+	} else {
+		err = w.walk(root, fns)
+	}
+	if err == SkipChildren || err == SkipSiblings {
+		return nil
+	}
+	return err
+}
+
+func (w *dfsWalker) walk(el Pair, fns WalkFuncs) error {
+	in, ok := w.structured(el)
+	if !ok {
+		return visit(fns.Leaf, el)
+	}
+
+	var err error
+	if fns.Enter != nil {
+		err = fns.Enter(el)
+
+		// The value may have been replaced through Set.
+		in, ok = w.structured(el)
+	}
+	switch {
+	case err == nil && ok:
+		err = w.expand(el, in, func(child Pair) error {
+			err := w.walk(child, fns)
+			if err == nil || err == SkipSiblings {
+				w.release(child)
+			}
+			return err
+		})
+		if err != nil && err != SkipSiblings {
+			return err
+		}
+	case err != nil && err != SkipChildren:
+		return err
+	}
+	return visit(fns.Exit, el)
+}
+
+// walkStack visits the same Pairs as walk, using a stepper to avoid recursion.
+func (w *dfsWalker) walkStack(root Pair, fns WalkFuncs) error {
+	s := newStepper(&w.walker, root)
+	for {
+		ev, el, err := s.step()
+		switch ev {
+		case stepDone:
+			return err
+		case stepLeaf:
+			err = visit(fns.Leaf, el)
+		case stepEnter:
+			if fns.Enter != nil {
+				err = fns.Enter(el)
+			}
+			err = s.descend(el, err)
+		case stepExit:
+			err = visit(fns.Exit, el)
+		}
+		switch {
+		case err == SkipSiblings:
+			s.skipSiblings()
+		case err != nil:
+			return err
+		}
+	}
+}
+
+// visit calls f with el if it is non-nil, a SkipChildren error is discarded
+// since it has no meaning outside of entering a structured value.
+func visit(f func(Pair) error, el Pair) error {
+	if f == nil {
+		return nil
+	}
+	if err := f(el); err != SkipChildren {
+		return err
+	}
+	return nil
+}
+
+type bfsWalker struct {
+	walker
+}
+
+func (w bfsWalker) WalkContext(ctx context.Context, value interface{},
+	f func(el Pair) error) error {
+	w.ctx = ctx
+	return w.Walk(value, f)
+}
+
+func (w bfsWalker) Walk(value interface{}, f func(el Pair) error) error {
+	root := newRootPair(value)
+	if err := w.canceled(root); err != nil {
+		return err
+	}
+	queue := []Pair{root}
+	enqueue := func(child Pair) error {
+		queue = append(queue, child)
+		return nil
+	}
+
+	// Siblings are always adjacent within the queue, so skipping them only
+	// requires remembering the parent they were enqueued by.
+	var skip Pair
+	for len(queue) > 0 {
+		el := queue[0]
+		queue[0] = nil
+		queue = queue[1:]
+		if skip != nil && el.Parent() == skip {
+			continue
+		}
+		if err := w.canceled(el); err != nil {
+			return err
+		}
+
+		in, ok := w.structured(el)
+		if ok && !w.containers {
+			if err := w.expand(el, in, enqueue); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch err := f(el); {
+		case err == SkipSiblings:
+			skip = el.Parent()
+		case err == SkipChildren:
+		case err != nil:
+			return err
+		case ok:
+			// The value may have been replaced through Set.
+			if in, ok = w.structured(el); !ok {
+				continue
+			}
+			if err := w.expand(el, in, enqueue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walker holds the visiting rules shared by each Walker implementation, they
+// only differ in the order they walk the Pairs given by expand. Walkers have
+// value receivers, so ctx is only set on the copy used for a single walk.
+type walker struct {
+	Iterator
+	ctx          context.Context
+	containers   bool
+	skipCycles   bool
+	inaccessible bool
+	maxDepth     int
+	pool         bool
+	iterative    bool
+	opaque       *opaqueTypes
+
+	// free holds the Pairs released during a walk when pooling.
+	free []*pair
+
+	// refs holds the ancestors of the Pairs being yielded by their ref, for
+	// walks that maintain it rather than searching each parent.
+	refs map[ref]Pair
+}
+
+func newWalker(iterator Iterator, opts []WalkerOption) walker {
+	w := walker{Iterator: iterator}
+	for _, opt := range opts {
+		opt(&w)
+	}
+	return w
+}
+
+// canceled returns a PathError for el if the context of this walk is done.
+func (w *walker) canceled(el Pair) error {
+	if w.ctx == nil {
+		return nil
+	}
+	select {
+	case <-w.ctx.Done():
+		return &PathError{Path: el.Path(), Err: w.ctx.Err()}
+	default:
+		return nil
+	}
+}
+
+// newPair returns a zeroed pair, reusing one that has been released during this
+// walk if possible.
+func (w *walker) newPair() *pair {
+	if n := len(w.free); n > 0 {
+		pr := w.free[n-1]
+		w.free = w.free[:n-1]
+		return pr
+	}
+	return &pair{pooled: true}
+}
+
+// release allows el to be reused by newPair if pooling, it must only be called
+// once the walk func is done with el and each of its children.
+func (w *walker) release(el Pair) {
+	if !w.pool {
+		return
+	}
+	if pr, ok := el.(*pair); ok && pr.pooled {
+		*pr = pair{pooled: true}
+		w.free = append(w.free, pr)
+	}
+}
+
+func newRootPair(value interface{}) Pair {
+	return &pair{
 		key: nil,
 		val: value,
 		pnt: nil,
 		err: nil,
+		ref: refOf(value),
+		rv:  reflect.ValueOf(value),
+	}
+}
+
+// yield calls f with pr, a new child Pair of el. If the value of pr refers to
+// a structured value held by any ancestor it will hold a CycleError, or not be
+// visited at all when skipping cycles.
+func (w *walker) yield(el Pair, pr *pair, f func(Pair) error) error {
+	pr.pnt = el
+	if err := w.canceled(pr); err != nil {
+		return err
+	}
+	pr.val = pr.rv.Interface()
+	if pr.mv.IsValid() && pr.rv.CanAddr() {
+		// The Iterator reuses the Value for each element of the map, such as
+		// Iter with ReuseMapValues. It is replaced by the copy held in val, as
+		// elements of maps are never addressable otherwise.
+		pr.rv = valueOf(pr.val, pr.rv.Type())
+	}
+	if pr.ref = refOf(pr.val); pr.ref.ptr == 0 {
+		return f(pr)
+	}
+	if pnt := w.ancestor(el, pr.ref); pnt != nil {
+		if w.skipCycles {
+			w.release(pr)
+			return nil
+		}
+		pr.err = &CycleError{Ancestor: pnt}
+	}
+	return f(pr)
+}
+
+// ancestor returns el or the first of its parents that holds the value r refers
+// to, or nil if there is none.
+func (w *walker) ancestor(el Pair, r ref) Pair {
+	if w.refs != nil {
+		return w.refs[r]
+	}
+	for ; el != nil; el = el.Parent() {
+		if pr, ok := el.(*pair); ok && pr.ref == r {
+			return pr
+		}
+	}
+	return nil
+}
+
+// skip is called by the visit funcs for values that can't be accessed, they
+// are skipped or given to f in a Pair holding an InaccessibleError.
+func (w *walker) skip(el Pair, pr *pair, name string,
+	f func(Pair) error) error {
+	if !w.inaccessible {
+		w.release(pr)
+		return nil
 	}
-	return w.walk(root, f)
+	pr.pnt = el
+	if err := w.canceled(pr); err != nil {
+		return err
+	}
+	pr.err = &InaccessibleError{
+		Name: name, Kind: pr.rv.Kind(), Path: el.Path()}
+	return f(pr)
 }
 
-func (w dfsWalker) walk(el Pair, f func(Pair) error) error {
-	in := reflect.ValueOf(indirect(el.Val()))
+// structured returns the indirect value held by el and reports if it is a
+// structured type that may be given to expand. Pairs holding an error are
+// never structured.
+func (w *walker) structured(el Pair) (reflect.Value, bool) {
+	if el.Err() != nil {
+		return reflect.Value{}, false
+	}
+
+	// Values such as strings and pointers to them are never structured, so
+	// the plan of their type avoids following them.
+	in := el.Value()
+	if !in.IsValid() {
+		return in, false
+	}
+	if in.Kind() == reflect.Ptr && planOf(in.Type()).leaf {
+		return in, false
+	}
+
+	if w.opaque != nil && w.opaque.has(in.Type()) {
+		return in, false
+	}
+
+	// The Value is followed directly so it remains addressable, allowing Set
+	// to modify the children.
+	in = indirectValue(in)
+	if w.opaque != nil && w.opaque.has(in.Type()) {
+		return in, false
+	}
 
+	switch in.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Struct, reflect.Chan, reflect.Map:
+	default:
+		if _, ok := walkableOf(in, false); !ok {
+			return in, false
+		}
+	}
+	if w.maxDepth > 0 && el.Depth() >= w.maxDepth {
+		if pr, ok := el.(*pair); ok {
+			pr.trunc = true
+		}
+		return in, false
+	}
+	return in, true
+}
+
+// expand will call f with a Pair for each child element of the structured
+// value in, which is held by el, using the underlying Iterator. Values that
+// implement Walkable and containers of the standard library are expanded on
+// their own, see expandContainer.
+func (w *walker) expand(el Pair, in reflect.Value, f func(Pair) error) error {
+	if wk, ok := walkableOf(in, true); ok {
+		return wk.Walk(el, w.walkableVisitFunc(el, f))
+	}
+	if ok, err := w.expandContainer(el, in, f); ok {
+		return err
+	}
 	switch in.Kind() {
 	case reflect.Slice, reflect.Array:
-		return w.IterSlice(in, w.seqVisitFunc(el, f))
+		return w.IterSlice(in, w.seqVisitFunc(el, in.Kind(), f))
 	case reflect.Struct:
-		return w.IterStruct(in, w.structVisitFunc(el, f))
+		return w.IterStruct(in, w.structVisitFunc(el, planOf(in.Type()), f))
 	case reflect.Chan:
-		return w.IterChan(in, w.seqVisitFunc(el, f))
+		it, ok := w.Iterator.(ContextIterator)
+		if !ok || w.ctx == nil {
+			return w.IterChan(in, w.seqVisitFunc(el, in.Kind(), f))
+		}
+		err := it.IterChanContext(w.ctx, in, w.seqVisitFunc(el, in.Kind(), f))
+		if err != nil && err == w.ctx.Err() {
+			return &PathError{Path: el.Path(), Err: err}
+		}
+		return err
 	case reflect.Map:
-		return w.IterMap(in, w.mapVisitFunc(el, f))
+		return w.IterMap(in, w.mapVisitFunc(el, in, f))
+	default:
+		return nil
+	}
+}
+
+// walkableOf returns in as a Walkable if it, or a pointer to it, implements
+// Walkable. When only a pointer does and in is not addressable the Walkable
+// is a copy of in, which is only created when convert is true.
+func walkableOf(in reflect.Value, convert bool) (Walkable, bool) {
+	if !in.IsValid() || !in.CanInterface() {
+		return nil, false
+	}
+	switch in.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if in.IsNil() {
+			return nil, false
+		}
+	}
+
+	tp := planOf(in.Type())
+	switch {
+	case !tp.walkable && !tp.walkablePtr:
+		return nil, false
+	case !convert:
+		return nil, true
+	case tp.walkable:
+		return in.Interface().(Walkable), true
+	case in.CanAddr():
+		return in.Addr().Interface().(Walkable), true
 	default:
-		return f(el)
+		ptr := reflect.New(in.Type())
+		ptr.Elem().Set(in)
+		return ptr.Interface().(Walkable), true
+	}
+}
+
+// walkableVisitFunc returns the func given to the Walk method of a Walkable
+// held by el, which checks the Pairs it creates for cycles like yield.
+func (w *walker) walkableVisitFunc(el Pair, f func(Pair) error) func(Pair) error {
+	return func(child Pair) error {
+		if err := w.canceled(child); err != nil {
+			return err
+		}
+		pr, ok := child.(*pair)
+		if !ok || pr.err != nil || pr.ref.ptr == 0 {
+			return f(child)
+		}
+		if pnt := w.ancestor(el, pr.ref); pnt != nil {
+			if w.skipCycles {
+				return nil
+			}
+			pr.err = &CycleError{Ancestor: pnt}
+		}
+		return f(child)
 	}
 }
 
 type structVisitFn func(field reflect.StructField, value reflect.Value) error
 
-func (w dfsWalker) structVisitFunc(el Pair, f func(Pair) error) structVisitFn {
+func (w *walker) structVisitFunc(el Pair, tp *typePlan,
+	f func(Pair) error) structVisitFn {
 	return func(s reflect.StructField, v reflect.Value) error {
+		pr := w.newPair()
+		pr.key, pr.rv, pr.via = tp.fieldKey(s), v, reflect.Struct
 		if !v.IsValid() || !v.CanInterface() {
-			return nil
+			return w.skip(el, pr, s.Name, f)
 		}
-		return w.walk(&pair{s, v.Interface(), el, nil}, f)
+		return w.yield(el, pr, f)
 	}
 }
 
 type seqVisitFunc func(idx int, value reflect.Value) error
 
-func (w dfsWalker) seqVisitFunc(el Pair, f func(Pair) error) seqVisitFunc {
+func (w *walker) seqVisitFunc(el Pair, via reflect.Kind,
+	f func(Pair) error) seqVisitFunc {
 	return func(idx int, v reflect.Value) error {
+		pr := w.newPair()
+		pr.key, pr.rv, pr.via = idx, v, via
 		if !v.IsValid() || !v.CanInterface() {
-			return nil
+			return w.skip(el, pr, fmt.Sprintf("[%d]", idx), f)
 		}
-		return w.walk(&pair{idx, v.Interface(), el, nil}, f)
+		return w.yield(el, pr, f)
 	}
 }
 
 type mapVisitFunc func(key, value reflect.Value) error
 
-func (w dfsWalker) mapVisitFunc(el Pair, f func(Pair) error) mapVisitFunc {
+func (w *walker) mapVisitFunc(el Pair, in reflect.Value,
+	f func(Pair) error) mapVisitFunc {
 	return func(k, v reflect.Value) error {
-		if !k.IsValid() || !v.IsValid() ||
-			!k.CanInterface() || !v.CanInterface() {
-			return nil
+		pr := w.newPair()
+		pr.rv, pr.via, pr.mv = v, reflect.Map, in
+		if !k.IsValid() || !k.CanInterface() {
+			return w.skip(el, pr, "[?]", f)
+		}
+		pr.key = k.Interface()
+		if !v.IsValid() || !v.CanInterface() {
+			return w.skip(el, pr, MapKey{pr.key}.String(), f)
 		}
-		return w.walk(&pair{k.Interface(), v.Interface(), el, nil}, f)
+		return w.yield(el, pr, f)
 	}
 }
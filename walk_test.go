@@ -3,9 +3,14 @@ package iter
 import (
 	"bytes"
 	"container/ring"
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 )
 
 type TestTree struct {
@@ -14,7 +19,15 @@ type TestTree struct {
 }
 
 func (t *TestTree) Walk(parent Pair, f func(el Pair) error) error {
-	return f(NewPair(parent, "foo", "bar", nil))
+	if err := f(NewPair(parent, "At", t.At, nil)); err != nil {
+		return err
+	}
+	for i, c := range t.Children {
+		if err := f(NewPair(parent, i, c, nil)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func newTestTree() *TestTree {
@@ -116,8 +129,8 @@ func TestDfsWalker(t *testing.T) {
 			if err != nil {
 				t.Fatalf("expected nil err, got: %v", err)
 			}
-			if i != 1 {
-				t.Fatalf("expected exactly 1 visit, got: %v", i)
+			if i != 10 {
+				t.Fatalf("expected exactly 10 visits, got: %v", i)
 			}
 		}
 	})
@@ -162,3 +175,1023 @@ func TestDfsWalker(t *testing.T) {
 		}
 	})
 }
+
+func TestBfsWalker(t *testing.T) {
+	t.Run("Walk", func(t *testing.T) {
+		w := NewBFSWalker(&Iter{ChanRecv: true})
+		depth, at := 0, 0
+		err := w.Walk(newTestTree(), func(el Pair) error {
+			if el.Depth() < depth {
+				t.Fatalf("expected depth >= %v, got: %v", depth, el.Depth())
+			}
+			depth = el.Depth()
+
+			if el.Key() != "At" {
+				return nil
+			}
+			if el.Val().(int) < at {
+				t.Fatalf("expected tree level >= %v, got: %v", at, el.Val())
+			}
+			at = el.Val().(int)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if at != 3 {
+			t.Fatalf("expected to reach tree level 3, got: %v", at)
+		}
+	})
+
+	t.Run("SameAsDfs", func(t *testing.T) {
+		v := map[string]interface{}{
+			"a": []interface{}{1, []int{2, 3}, map[string]int{"b": 4}},
+			"c": struct{ D, E interface{} }{5, []int{6}},
+			"f": 7,
+		}
+		visit := func(w Walker) map[string]int {
+			res := make(map[string]int)
+			err := w.Walk(v, func(el Pair) error {
+				var path []string
+				for pnt := el; pnt.Parent() != nil; pnt = pnt.Parent() {
+					path = append(path, fmt.Sprintf("%v", pnt.Key()))
+				}
+				res[fmt.Sprintf("%v=%v", path, el.Val())] = el.Depth()
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("expected nil err, got: %v", err)
+			}
+			return res
+		}
+
+		exp := visit(NewWalker(NewIter()))
+		got := visit(NewBFSWalker(NewIter()))
+		if len(exp) != 7 {
+			t.Fatalf("expected 7 visits, got: %v", len(exp))
+		}
+		if !reflect.DeepEqual(exp, got) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, got)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		expErr := errors.New("propagate error")
+		w := NewBFSWalker(NewIter())
+		i := 0
+		err := w.Walk([]interface{}{1, []int{2, 3}, 4}, func(el Pair) error {
+			i++
+			if el.Depth() > 1 {
+				return expErr
+			}
+			return nil
+		})
+		if err != expErr {
+			t.Fatalf("expected err %v, got: %v", expErr, err)
+		}
+		if i != 3 {
+			t.Fatalf("expected exactly 3 visits, got: %v", i)
+		}
+	})
+}
+
+// testWalkers holds a constructor for each Walker backed by NewIter, for tests
+// which expect every Walker to give the same results.
+var testWalkers = map[string]func(opts ...WalkerOption) Walker{
+	"Dfs": func(opts ...WalkerOption) Walker {
+		return NewWalker(NewIter(), opts...)
+	},
+	"Bfs": func(opts ...WalkerOption) Walker {
+		return NewBFSWalker(NewIter(), opts...)
+	},
+}
+
+func TestWalkerSkip(t *testing.T) {
+	type testWalkerSkip struct {
+		A []int
+		B map[string]int
+		C []int
+		D int
+	}
+	give := &testWalkerSkip{
+		A: []int{1, 2, 3},
+		B: map[string]int{"b": 4},
+		C: []int{5, 6, 7},
+		D: 8,
+	}
+	for name, wf := range testWalkers {
+		name, wf := name, wf
+		t.Run(name, func(t *testing.T) {
+			t.Run("Containers", func(t *testing.T) {
+				var res []interface{}
+				err := wf(VisitContainers()).Walk(give, func(el Pair) error {
+					if el.Parent() == nil {
+						return nil
+					}
+					sf, ok := el.Key().(reflect.StructField)
+					if ok && sf.Name == "B" {
+						return SkipChildren
+					}
+					if ok && sf.Name != "D" {
+						res = append(res, sf.Name)
+						return nil
+					}
+					res = append(res, el.Val())
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("expected nil err, got: %v", err)
+				}
+				if len(res) != 9 {
+					t.Fatalf("expected 9 visits, got: %v", res)
+				}
+			})
+			t.Run("SkipSiblings", func(t *testing.T) {
+				var res []interface{}
+				err := wf().Walk(give, func(el Pair) error {
+					if el.Val() == 2 || el.Val() == 6 {
+						return SkipSiblings
+					}
+					res = append(res, el.Val())
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("expected nil err, got: %v", err)
+				}
+				sort.Slice(res, func(i, j int) bool {
+					return res[i].(int) < res[j].(int)
+				})
+				if exp := []interface{}{1, 4, 5, 8}; !reflect.DeepEqual(exp, res) {
+					t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+				}
+			})
+			t.Run("SkipSiblingsContainer", func(t *testing.T) {
+				var res []interface{}
+				err := wf(VisitContainers()).Walk(give, func(el Pair) error {
+					if sf, ok := el.Key().(reflect.StructField); ok && sf.Name == "B" {
+						return SkipSiblings
+					}
+					if el.Parent() != nil && el.Parent().Parent() != nil {
+						res = append(res, el.Val())
+					}
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("expected nil err, got: %v", err)
+				}
+				if exp := []interface{}{1, 2, 3}; !reflect.DeepEqual(exp, res) {
+					t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+				}
+			})
+			t.Run("Root", func(t *testing.T) {
+				for _, skip := range []error{SkipChildren, SkipSiblings} {
+					i := 0
+					err := wf(VisitContainers()).Walk(give, func(el Pair) error {
+						i++
+						return skip
+					})
+					if err != nil {
+						t.Fatalf("expected nil err, got: %v", err)
+					}
+					if i != 1 {
+						t.Fatalf("expected exactly 1 visit, got: %v", i)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestTraverse(t *testing.T) {
+	var _ Traverser = dfsWalker{}
+	var _ Traverser = NewWalker(NewIter()).(Traverser)
+
+	type testTraverse struct {
+		A []int
+		B struct{ C string }
+	}
+	give := testTraverse{A: []int{1, 2}}
+	give.B.C = "c"
+
+	name := func(el Pair) string {
+		if sf, ok := el.Key().(reflect.StructField); ok {
+			return sf.Name
+		}
+		return fmt.Sprintf("%v", el.Key())
+	}
+	trace := func(res *[]string, prefix string, err error) func(el Pair) error {
+		return func(el Pair) error {
+			*res = append(*res, prefix+name(el))
+			if el.Key() == 1 {
+				return err
+			}
+			return nil
+		}
+	}
+
+	t.Run("Events", func(t *testing.T) {
+		var res []string
+		err := Traverse(give, WalkFuncs{
+			Enter: trace(&res, ">", nil),
+			Leaf:  trace(&res, "=", nil),
+			Exit:  trace(&res, "<", nil),
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		exp := []string{"><nil>", ">A", "=0", "=1", "<A", ">B", "=C", "<B", "<<nil>"}
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+	t.Run("NilFuncs", func(t *testing.T) {
+		var res []string
+		err := Traverse(give, WalkFuncs{Exit: trace(&res, "<", nil)})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		exp := []string{"<A", "<B", "<<nil>"}
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+	t.Run("SkipChildren", func(t *testing.T) {
+		var res []string
+		err := Traverse(give, WalkFuncs{
+			Enter: func(el Pair) error {
+				res = append(res, ">"+name(el))
+				if name(el) == "A" {
+					return SkipChildren
+				}
+				return nil
+			},
+			Leaf: trace(&res, "=", nil),
+			Exit: trace(&res, "<", nil),
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		exp := []string{"><nil>", ">A", "<A", ">B", "=C", "<B", "<<nil>"}
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+	t.Run("SkipSiblings", func(t *testing.T) {
+		var res []string
+		err := Traverse([][]int{{0, 1, 2}, {3}}, WalkFuncs{
+			Leaf: trace(&res, "=", SkipSiblings),
+			Exit: trace(&res, "<", SkipSiblings),
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		exp := []string{"=0", "=1", "<0", "=0", "<1", "<<nil>"}
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+	t.Run("Error", func(t *testing.T) {
+		expErr := errors.New("propagate error")
+		for _, fns := range []WalkFuncs{
+			{Enter: func(el Pair) error { return expErr }},
+			{Leaf: func(el Pair) error { return expErr }},
+			{Exit: func(el Pair) error { return expErr }},
+		} {
+			if err := Traverse(give, fns); err != expErr {
+				t.Fatalf("expected err %v, got: %v", expErr, err)
+			}
+		}
+	})
+}
+
+func TestWalkerCycles(t *testing.T) {
+	type testCycleNode struct {
+		Val        int
+		Prev, Next *testCycleNode
+	}
+	var nodes [3]testCycleNode
+	for i := range nodes {
+		nodes[i].Val = i
+		if i > 0 {
+			nodes[i].Prev = &nodes[i-1]
+			nodes[i-1].Next = &nodes[i]
+		}
+	}
+	m := map[string]interface{}{"a": 1}
+	m["m"] = m
+	sl := []interface{}{1, nil}
+	sl[1] = sl
+
+	tests := []struct {
+		name   string
+		give   interface{}
+		leaves int
+		cycles int
+	}{
+		{"List", &nodes[0], 5, 2},
+		{"ListMiddle", &nodes[1], 5, 2},
+		{"Map", m, 1, 1},
+		{"Slice", sl, 1, 1},
+		{"NotCycle", []*testCycleNode{&nodes[2], &nodes[2]}, 10, 4},
+	}
+	for name, wf := range testWalkers {
+		for _, tc := range tests {
+			tc, wf := tc, wf
+			t.Run(name+"/"+tc.name, func(t *testing.T) {
+				var leaves, cycles int
+				err := wf().Walk(tc.give, func(el Pair) error {
+					if err := el.Err(); err != nil {
+						cerr, ok := err.(*CycleError)
+						if !ok {
+							t.Fatalf("expected *CycleError, got: %v", err)
+						}
+						if refOf(cerr.Ancestor.Val()) != refOf(el.Val()) {
+							t.Fatalf("expected ancestor holding %v, got: %v", el.Val(), cerr.Ancestor)
+						}
+						cycles++
+						return nil
+					}
+					leaves++
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("expected nil err, got: %v", err)
+				}
+				if leaves != tc.leaves {
+					t.Errorf("expected %v leaves, got: %v", tc.leaves, leaves)
+				}
+				if cycles != tc.cycles {
+					t.Errorf("expected %v cycles, got: %v", tc.cycles, cycles)
+				}
+
+				leaves = 0
+				err = wf(SkipCycles()).Walk(tc.give, func(el Pair) error {
+					if err := el.Err(); err != nil {
+						t.Fatalf("expected nil err, got: %v", err)
+					}
+					leaves++
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("expected nil err, got: %v", err)
+				}
+				if leaves != tc.leaves {
+					t.Errorf("expected %v leaves, got: %v", tc.leaves, leaves)
+				}
+			})
+		}
+	}
+}
+
+func TestWalkerInaccessible(t *testing.T) {
+	type testInaccessibleInner struct {
+		Name   string
+		secret []int
+	}
+	type testInaccessible struct {
+		Public string
+		hidden int
+		Inner  testInaccessibleInner
+	}
+	give := testInaccessible{"a", 1, testInaccessibleInner{"b", []int{2}}}
+
+	t.Run("Skipped", func(t *testing.T) {
+		var res []interface{}
+		err := NewWalker(NewIter()).Walk(give, func(el Pair) error {
+			res = append(res, el.Val())
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if exp := []interface{}{"a", "b"}; !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("Reported", func(t *testing.T) {
+		var errs []string
+		w := NewWalker(NewIter(), ReportInaccessible())
+		err := w.Walk(give, func(el Pair) error {
+			err := el.Err()
+			if err == nil {
+				return nil
+			}
+			ierr, ok := err.(*InaccessibleError)
+			if !ok {
+				t.Fatalf("expected *InaccessibleError, got: %v", err)
+			}
+			if el.Val() != nil {
+				t.Fatalf("expected nil value, got: %v", el.Val())
+			}
+			if !reflect.DeepEqual(ierr.Path, el.Parent().Path()) {
+				t.Fatalf("expected path %v, got: %v", el.Parent().Path(), ierr.Path)
+			}
+			errs = append(errs, err.Error())
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		exp := []string{
+			"inaccessible int value hidden",
+			"inaccessible slice value secret in .Inner",
+		}
+		if !reflect.DeepEqual(exp, errs) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, errs)
+		}
+	})
+
+	t.Run("ExcludeUnexported", func(t *testing.T) {
+		w := NewWalker(&Iter{ExcludeUnexported: true}, ReportInaccessible())
+		err := w.Walk(give, func(el Pair) error {
+			return el.Err()
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		w := NewWalker(invalidIter{}, ReportInaccessible())
+		ch := make(chan string, 1)
+		ch <- "foo"
+		tests := []struct {
+			give interface{}
+			exp  string
+		}{
+			{ch, "inaccessible invalid value [0]"},
+			{map[int]string{1: "123"}, "inaccessible invalid value [?]"},
+			{[]string{"123"}, "inaccessible invalid value [0]"},
+			{struct{ name string }{"123"}, "inaccessible invalid value "},
+		}
+		for _, tc := range tests {
+			var errs []string
+			err := w.Walk(tc.give, func(el Pair) error {
+				errs = append(errs, el.Err().Error())
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("expected nil err, got: %v", err)
+			}
+			if exp := []string{tc.exp}; !reflect.DeepEqual(exp, errs) {
+				t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, errs)
+			}
+		}
+	})
+}
+
+func TestWalkContext(t *testing.T) {
+	var _ ContextWalker = NewWalker(NewIter()).(ContextWalker)
+	var _ ContextWalker = NewBFSWalker(NewIter()).(ContextWalker)
+
+	give := map[string][]int{"a": {1, 2, 3}}
+	walkers := map[string]ContextWalker{
+		"Dfs": NewWalker(NewIter()).(ContextWalker),
+		"Bfs": NewBFSWalker(NewIter()).(ContextWalker),
+	}
+	for name, w := range walkers {
+		w := w
+		t.Run(name, func(t *testing.T) {
+			t.Run("Canceled", func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				i := 0
+				err := w.WalkContext(ctx, give, func(el Pair) error {
+					i++
+					if el.Val() == 2 {
+						cancel()
+					}
+					return nil
+				})
+				if !errors.Is(err, context.Canceled) {
+					t.Fatalf("expected err %v, got: %v", context.Canceled, err)
+				}
+				perr, ok := err.(*PathError)
+				if !ok {
+					t.Fatalf("expected *PathError, got: %v", err)
+				}
+				if exp := `["a"][2]`; perr.Path.String() != exp {
+					t.Fatalf("expected path %v, got: %v", exp, perr.Path)
+				}
+				if exp := `walk stopped at ["a"][2]: context canceled`; err.Error() != exp {
+					t.Fatalf("expected err %v, got: %v", exp, err)
+				}
+				if i != 2 {
+					t.Fatalf("expected exactly 2 visits, got: %v", i)
+				}
+			})
+			t.Run("Done", func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				err := w.WalkContext(ctx, give, func(el Pair) error {
+					t.Fatal("expected no visits")
+					return nil
+				})
+				if exp := `walk stopped at root: context canceled`; err == nil || err.Error() != exp {
+					t.Fatalf("expected err %v, got: %v", exp, err)
+				}
+			})
+			t.Run("Background", func(t *testing.T) {
+				i := 0
+				err := w.WalkContext(context.Background(), give, func(el Pair) error {
+					i++
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("expected nil err, got: %v", err)
+				}
+				if i != 3 {
+					t.Fatalf("expected exactly 3 visits, got: %v", i)
+				}
+			})
+		})
+	}
+
+	t.Run("BlockingChan", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+
+		ch := make(chan int, 1)
+		ch <- 1
+		w := NewWalker(&Iter{ChanRecv: true, ChanBlock: true}).(ContextWalker)
+		err := w.WalkContext(ctx, struct{ Ch chan int }{ch}, func(el Pair) error {
+			return nil
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected err %v, got: %v", context.DeadlineExceeded, err)
+		}
+		if exp := `walk stopped at .Ch: context deadline exceeded`; err.Error() != exp {
+			t.Fatalf("expected err %v, got: %v", exp, err)
+		}
+	})
+
+	t.Run("WalkContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := WalkContext(ctx, give, func(el Pair) error {
+			return nil
+		}); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected err %v, got: %v", context.Canceled, err)
+		}
+	})
+}
+
+func TestWalkerMaxDepth(t *testing.T) {
+	give := map[string]interface{}{
+		"a": 1,
+		"b": []interface{}{2, []int{3}},
+		"c": struct{ D map[string]int }{map[string]int{"e": 4}},
+	}
+	tests := []struct {
+		max int
+		exp map[string]bool
+	}{
+		{0, map[string]bool{`["a"]`: false, `["b"][0]`: false, `["b"][1][0]`: false,
+			`["c"].D["e"]`: false}},
+		{-1, map[string]bool{`["a"]`: false, `["b"][0]`: false, `["b"][1][0]`: false,
+			`["c"].D["e"]`: false}},
+		{1, map[string]bool{`["a"]`: false, `["b"]`: true, `["c"]`: true}},
+		{2, map[string]bool{`["a"]`: false, `["b"][0]`: false, `["b"][1]`: true,
+			`["c"].D`: true}},
+		{3, map[string]bool{`["a"]`: false, `["b"][0]`: false, `["b"][1][0]`: false,
+			`["c"].D["e"]`: false}},
+	}
+	for name, wf := range testWalkers {
+		for _, tc := range tests {
+			tc, wf := tc, wf
+			t.Run(fmt.Sprintf("%v/MaxDepth[%v]", name, tc.max), func(t *testing.T) {
+				res := make(map[string]bool)
+				err := wf(MaxDepth(tc.max)).Walk(give, func(el Pair) error {
+					if tc.max > 0 && el.Depth() > tc.max {
+						t.Fatalf("expected depth <= %v, got: %v", tc.max, el.Depth())
+					}
+					res[el.Path().String()] = el.Truncated()
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("expected nil err, got: %v", err)
+				}
+				if !reflect.DeepEqual(tc.exp, res) {
+					t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", tc.exp, res)
+				}
+			})
+		}
+	}
+
+	t.Run("Traverse", func(t *testing.T) {
+		var res []string
+		w := NewWalker(NewIter(), MaxDepth(1)).(Traverser)
+		err := w.Traverse([][]int{{1}}, WalkFuncs{
+			Enter: func(el Pair) error {
+				res = append(res, "enter"+el.Path().String())
+				return nil
+			},
+			Leaf: func(el Pair) error {
+				res = append(res, fmt.Sprintf("leaf%v=%v", el.Path(), el.Truncated()))
+				return nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if exp := []string{"enter", "leaf[0]=true"}; !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+}
+
+func TestWalkerPoolPairs(t *testing.T) {
+	give := map[string]interface{}{
+		"a": []int{1, 2, 3},
+		"b": []interface{}{4, []int{5, 6}},
+		"c": &TestTree{At: 1, Children: []*TestTree{{At: 2}, {At: 3}}},
+	}
+	visit := func(w Walker) (map[string]interface{}, []Pair, int) {
+		res := make(map[string]interface{})
+		seen := make(map[Pair]bool)
+		var clones []Pair
+		err := w.Walk(give, func(el Pair) error {
+			res[el.Path().String()] = el.Val()
+			clones = append(clones, el.Clone())
+			seen[el] = true
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		return res, clones, len(seen)
+	}
+
+	exp, _, n := visit(NewWalker(NewIter()))
+	res, clones, pn := visit(NewWalker(NewIter(), PoolPairs()))
+	if !reflect.DeepEqual(exp, res) {
+		t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+	}
+	if pn >= n {
+		t.Fatalf("expected fewer than %v distinct pairs, got: %v", n, pn)
+	}
+	for _, el := range clones {
+		if got := exp[el.Path().String()]; !reflect.DeepEqual(got, el.Val()) {
+			t.Fatalf("expected clone %v to hold %v, got: %v", el.Path(), got, el.Val())
+		}
+	}
+
+	t.Run("Cycles", func(t *testing.T) {
+		type testCycleNode struct{ Next *testCycleNode }
+		node := &testCycleNode{}
+		node.Next = &testCycleNode{node}
+
+		var cycle Pair
+		err := NewWalker(NewIter(), PoolPairs()).Walk(node, func(el Pair) error {
+			if el.Err() != nil {
+				cycle = el.Clone()
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if cycle == nil {
+			t.Fatal("expected a cycle to be visited")
+		}
+		var ce *CycleError
+		if !errors.As(cycle.Err(), &ce) {
+			t.Fatalf("expected CycleError, got: %v", cycle.Err())
+		}
+		if ce.Ancestor != cycle.Parent().Parent() {
+			t.Fatalf("expected ancestor to be a clone of the root, got: %v",
+				ce.Ancestor)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var last Pair
+		err := NewWalker(NewIter(), PoolPairs()).Walk(give, func(el Pair) error {
+			last = el
+			return errors.New("stop")
+		})
+		if err == nil {
+			t.Fatal("expected non-nil err")
+		}
+		if last.Parent() == nil || last.Val() == nil {
+			t.Fatalf("expected pair returning an error to remain valid, got: %v",
+				last)
+		}
+	})
+
+	t.Run("Bfs", func(t *testing.T) {
+		exp, _, _ := visit(NewBFSWalker(NewIter()))
+		res, _, _ := visit(NewBFSWalker(NewIter(), PoolPairs()))
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+}
+
+func TestWalkerIterative(t *testing.T) {
+	type testIterativeNode struct {
+		Name string
+		Next *testIterativeNode
+		Tags map[string][]int
+	}
+	cyclic := &testIterativeNode{Name: "a"}
+	cyclic.Next = &testIterativeNode{Name: "b", Next: cyclic}
+	give := []interface{}{
+		1,
+		[]interface{}{"a", []int{2, 3}, map[string]int{"x": 4, "y": 5}},
+		&testIterativeNode{"n", &testIterativeNode{Name: "m"},
+			map[string][]int{"t": {6, 7}, "u": nil}},
+		cyclic,
+		struct{ A, B []string }{[]string{"c"}, []string{"d", "e"}},
+	}
+
+	errStop := errors.New("stop")
+	policies := map[string]func(event string, el Pair) error{
+		"Nil": func(event string, el Pair) error {
+			return nil
+		},
+		"SkipChildren": func(event string, el Pair) error {
+			if event == "enter" && el.Depth() == 2 {
+				return SkipChildren
+			}
+			return nil
+		},
+		"SkipSiblingsEnter": func(event string, el Pair) error {
+			if event == "enter" && el.Path().String() == "[1][1]" {
+				return SkipSiblings
+			}
+			return nil
+		},
+		"SkipSiblingsLeaf": func(event string, el Pair) error {
+			if event == "leaf" && el.Val() == "c" {
+				return SkipSiblings
+			}
+			return nil
+		},
+		"SkipSiblingsExit": func(event string, el Pair) error {
+			if event == "exit" && el.Path().String() == "[2].Next" {
+				return SkipSiblings
+			}
+			return nil
+		},
+		"SkipSiblingsRoot": func(event string, el Pair) error {
+			if event == "enter" && el.Depth() == 0 {
+				return SkipSiblings
+			}
+			return nil
+		},
+		"Error": func(event string, el Pair) error {
+			if event == "leaf" && el.Val() == 6 {
+				return errStop
+			}
+			return nil
+		},
+	}
+	options := map[string][]WalkerOption{
+		"Default":    nil,
+		"PoolPairs":  {PoolPairs()},
+		"MaxDepth":   {MaxDepth(2)},
+		"SkipCycles": {SkipCycles()},
+	}
+	traverse := func(w Walker, policy func(string, Pair) error) ([]string, error) {
+		var res []string
+		record := func(event string) func(el Pair) error {
+			return func(el Pair) error {
+				res = append(res, fmt.Sprintf("%v%v=%v/%v", event, el.Path(),
+					el.Truncated(), el.Err() != nil))
+				return policy(event, el)
+			}
+		}
+		err := w.(Traverser).Traverse(give, WalkFuncs{
+			Enter: record("enter"), Leaf: record("leaf"), Exit: record("exit")})
+		return res, err
+	}
+
+	for pname, policy := range policies {
+		for oname, opts := range options {
+			policy, opts := policy, opts
+			t.Run(pname+"/"+oname, func(t *testing.T) {
+				it := &Iter{SortMapKeys: true}
+				exp, expErr := traverse(NewWalker(it, opts...), policy)
+				iterOpts := append([]WalkerOption{Iterative()}, opts...)
+				res, err := traverse(NewWalker(it, iterOpts...), policy)
+				if expErr != err {
+					t.Fatalf("expected err %v, got: %v", expErr, err)
+				}
+				if !reflect.DeepEqual(exp, res) {
+					t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+				}
+			})
+		}
+	}
+
+	t.Run("Deep", func(t *testing.T) {
+		const depth = 100000
+		var list *testIterativeNode
+		for i := 0; i < depth; i++ {
+			list = &testIterativeNode{Next: list}
+		}
+
+		var leaves int
+		err := NewWalker(NewIter(), Iterative()).Walk(list, func(el Pair) error {
+			leaves++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if exp := depth + 1; leaves != exp {
+			t.Fatalf("expected %v leaves, got: %v", exp, leaves)
+		}
+	})
+
+	t.Run("ReuseMapValues", func(t *testing.T) {
+		give := map[string][]int{"a": {1}, "b": {2}, "c": {3}}
+		res := make(map[string]interface{})
+		w := NewWalker(&Iter{ReuseMapValues: true}, Iterative())
+		err := w.Walk(give, func(el Pair) error {
+			res[el.Path().String()] = el.Val()
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		exp := map[string]interface{}{`["a"][0]`: 1, `["b"][0]`: 2, `["c"][0]`: 3}
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var visits int
+		w := NewWalker(NewIter(), Iterative()).(ContextWalker)
+		err := w.WalkContext(ctx, [][]int{{1, 2}, {3, 4}}, func(el Pair) error {
+			if visits++; visits == 2 {
+				cancel()
+			}
+			return nil
+		})
+		var pe *PathError
+		if !errors.As(err, &pe) || pe.Err != context.Canceled {
+			t.Fatalf("expected PathError holding context.Canceled, got: %v", err)
+		}
+		if exp := "[1]"; pe.Path.String() != exp {
+			t.Fatalf("expected walk to stop at %v, got: %v", exp, pe.Path)
+		}
+		if visits != 2 {
+			t.Fatalf("expected 2 visits, got: %v", visits)
+		}
+	})
+}
+
+type testWalkableList struct {
+	head *testWalkableNode
+}
+
+type testWalkableNode struct {
+	val  interface{}
+	next *testWalkableNode
+}
+
+func newTestWalkableList(vals ...interface{}) *testWalkableList {
+	l := new(testWalkableList)
+	for i := len(vals) - 1; i >= 0; i-- {
+		l.head = &testWalkableNode{vals[i], l.head}
+	}
+	return l
+}
+
+func (l *testWalkableList) Walk(parent Pair, f func(el Pair) error) error {
+	i := 0
+	for n := l.head; n != nil; n = n.next {
+		if err := f(NewPair(parent, i, n.val, nil)); err != nil {
+			return err
+		}
+		i++
+	}
+	return nil
+}
+
+type testWalkableCount int
+
+func (c testWalkableCount) Walk(parent Pair, f func(el Pair) error) error {
+	for i := 0; i < int(c); i++ {
+		if err := f(NewPair(parent, i, i*10, nil)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestWalkable(t *testing.T) {
+	type testWalkable struct {
+		List  testWalkableList
+		Ptr   *testWalkableList
+		Count testWalkableCount
+		Iface interface{}
+	}
+	give := &testWalkable{
+		List:  *newTestWalkableList("a", []int{1, 2}),
+		Ptr:   newTestWalkableList("b"),
+		Count: 2,
+		Iface: newTestWalkableList(testWalkableCount(1)),
+	}
+	exp := map[string]interface{}{
+		".List[0]":     "a",
+		".List[1][0]":  1,
+		".List[1][1]":  2,
+		".Ptr[0]":      "b",
+		".Count[0]":    0,
+		".Count[1]":    10,
+		".Iface[0][0]": 0,
+	}
+
+	walkers := map[string]Walker{
+		"Dfs":       NewWalker(NewIter()),
+		"Iterative": NewWalker(NewIter(), Iterative()),
+		"Bfs":       NewBFSWalker(NewIter()),
+		"Parallel":  NewParallelWalker(NewIter(), 4),
+	}
+	for name, w := range walkers {
+		w := w
+		t.Run(name, func(t *testing.T) {
+			for _, v := range []interface{}{give, *give} {
+				res := make(map[string]interface{})
+				err := w.Walk(v, func(el Pair) error {
+					res[el.Path().String()] = el.Val()
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("expected nil err, got: %v", err)
+				}
+				if !reflect.DeepEqual(exp, res) {
+					t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+				}
+			}
+		})
+	}
+
+	t.Run("Cursor", func(t *testing.T) {
+		res := make(map[string]interface{})
+		for c := NewCursor(NewIter(), give); c.Next(); {
+			res[c.Pair().Path().String()] = c.Pair().Val()
+		}
+		if !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("Root", func(t *testing.T) {
+		var res []interface{}
+		err := Walk(testWalkableCount(3), func(el Pair) error {
+			res = append(res, el.Val())
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if exp := []interface{}{0, 10, 20}; !reflect.DeepEqual(exp, res) {
+			t.Fatalf("DeepEqual failed:\n  exp: %#v\n  got: %#v", exp, res)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		expErr := errors.New("propagate error")
+		var visits int
+		err := Walk(newTestWalkableList(1, 2, 3), func(el Pair) error {
+			if visits++; el.Val() == 2 {
+				return expErr
+			}
+			return nil
+		})
+		if err != expErr {
+			t.Fatalf("expected err %v, got: %v", expErr, err)
+		}
+		if visits != 2 {
+			t.Fatalf("expected 2 visits, got: %v", visits)
+		}
+	})
+
+	t.Run("Cycles", func(t *testing.T) {
+		l := newTestWalkableList(1)
+		l.head.next = &testWalkableNode{val: l}
+
+		var cycles int
+		err := Walk(l, func(el Pair) error {
+			var ce *CycleError
+			if errors.As(el.Err(), &ce) {
+				cycles++
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil err, got: %v", err)
+		}
+		if cycles != 1 {
+			t.Fatalf("expected 1 cycle, got: %v", cycles)
+		}
+	})
+}